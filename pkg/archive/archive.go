@@ -0,0 +1,33 @@
+package archive
+
+import (
+	"io"
+
+	"github.com/readium/go-toolkit/pkg/asset"
+)
+
+// Entry is a single file inside an Archive.
+type Entry interface {
+	// Path is the entry's path relative to the archive root, using "/" separators.
+	Path() string
+	// Length is the uncompressed size of the entry, or -1 if unknown ahead of extraction.
+	Length() int64
+	// Reader opens a stream to read the entry's decompressed content.
+	Reader() (io.ReadCloser, error)
+}
+
+// Archive gives access to the entries of a compressed or otherwise packaged asset.
+type Archive interface {
+	io.Closer
+	// Entries lists every file contained in the archive.
+	Entries() []Entry
+	// Entry looks up a single entry by path, returning an error if it doesn't exist.
+	Entry(path string) (Entry, error)
+}
+
+// Factory opens an Archive from a publication asset, when it recognizes the underlying format.
+type Factory interface {
+	// Open returns the Archive backing asset, or nil if this factory does not support its format.
+	// password is used for encrypted archives and may be empty.
+	Open(asset asset.PublicationAsset, password string) (Archive, error)
+}
@@ -0,0 +1,215 @@
+package archive
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/nwaples/rardecode"
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/asset"
+	"github.com/readium/go-toolkit/pkg/mediatype"
+)
+
+// RARArchiveFactory opens CBR/RAR archives, including multi-part volumes, behind the
+// same Archive interface as the ZIP-backed factory.
+type RARArchiveFactory struct{}
+
+func NewRARArchiveFactory() RARArchiveFactory {
+	return RARArchiveFactory{}
+}
+
+// Open implements Factory.
+func (f RARArchiveFactory) Open(a asset.PublicationAsset, password string) (Archive, error) {
+	if !a.MediaType().Equal(&mediatype.CBR) {
+		return nil, nil
+	}
+
+	source, ok := a.(interface{ Path() string })
+	if !ok {
+		return nil, errors.New("rar archives can only be opened from a local file path")
+	}
+
+	entries, err := indexRARVolumes(source.Path(), password)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed indexing RAR archive")
+	}
+
+	return &rarArchive{path: source.Path(), password: password, entries: entries}, nil
+}
+
+type rarEntryInfo struct {
+	path   string
+	length int64
+}
+
+// indexRARVolumes walks every volume of a (possibly multi-part) RAR archive once, up
+// front, to build the entry list without holding decoder state open.
+func indexRARVolumes(path, password string) ([]rarEntryInfo, error) {
+	rc, err := rardecode.OpenReader(path, password)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var entries []rarEntryInfo
+	for {
+		header, err := rc.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.IsDir {
+			continue
+		}
+		entries = append(entries, rarEntryInfo{
+			path:   rarEntryPath(header.Name),
+			length: header.UnPackedSize,
+		})
+	}
+	return entries, nil
+}
+
+func rarEntryPath(name string) string {
+	return path.Clean(strings.ReplaceAll(name, `\`, "/"))
+}
+
+// rarVolumeReader is the subset of *rardecode.ReadCloser that extract needs to walk a
+// (possibly multi-part) volume set and read the current entry. It's declared locally,
+// rather than naming rardecode's concrete return type, purely so extract can hold it
+// across calls in a struct field.
+type rarVolumeReader interface {
+	io.Reader
+	io.Closer
+	Next() (*rardecode.FileHeader, error)
+}
+
+// rarArchive implements Archive over a RAR/CBR file. rardecode only exposes sequential
+// reads, so extract keeps one decoder open across calls and advances it forward,
+// spooling every entry it passes to a temp file as it goes: a sequential read of an
+// n-page scan decodes each page once, not once per page read.
+type rarArchive struct {
+	path     string
+	password string
+	entries  []rarEntryInfo
+
+	mu        sync.Mutex
+	cache     map[string]string // entry path -> spooled temp file
+	reader    rarVolumeReader   // open decoder, positioned after the last cached entry
+	exhausted bool              // reader has reached EOF; every entry is cached
+}
+
+func (a *rarArchive) Entries() []Entry {
+	out := make([]Entry, 0, len(a.entries))
+	for _, e := range a.entries {
+		out = append(out, &rarEntry{archive: a, info: e})
+	}
+	return out
+}
+
+func (a *rarArchive) Entry(p string) (Entry, error) {
+	for _, e := range a.entries {
+		if e.path == p {
+			return &rarEntry{archive: a, info: e}, nil
+		}
+	}
+	return nil, errors.Errorf("entry not found: %s", p)
+}
+
+func (a *rarArchive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.reader != nil {
+		a.reader.Close()
+		a.reader = nil
+	}
+	for _, tmp := range a.cache {
+		os.Remove(tmp)
+	}
+	a.cache = nil
+	return nil
+}
+
+// extract returns the spooled temp file for name, decoding and caching any entries
+// between the reader's current position and name along the way. Because the reader
+// stays open across calls (advanced forward, never rewound), a caller reading entries in
+// archive order only ever decodes each one once, however many times extract is called.
+func (a *rarArchive) extract(name string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cache == nil {
+		a.cache = make(map[string]string)
+	}
+	if tmp, ok := a.cache[name]; ok {
+		return tmp, nil
+	}
+
+	for !a.exhausted {
+		if a.reader == nil {
+			rc, err := rardecode.OpenReader(a.path, a.password)
+			if err != nil {
+				return "", err
+			}
+			a.reader = rc
+		}
+
+		header, err := a.reader.Next()
+		if err == io.EOF {
+			a.reader.Close()
+			a.reader = nil
+			a.exhausted = true
+			break
+		}
+		if err != nil {
+			a.reader.Close()
+			a.reader = nil
+			return "", err
+		}
+		if header.IsDir {
+			continue
+		}
+
+		entryPath := rarEntryPath(header.Name)
+		tmp, err := os.CreateTemp("", "rar-entry-*")
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.Copy(tmp, a.reader); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return "", err
+		}
+		tmp.Close()
+		a.cache[entryPath] = tmp.Name()
+
+		if entryPath == name {
+			return tmp.Name(), nil
+		}
+	}
+
+	return "", errors.Errorf("entry not found while extracting: %s", name)
+}
+
+type rarEntry struct {
+	archive *rarArchive
+	info    rarEntryInfo
+}
+
+func (e *rarEntry) Path() string  { return e.info.path }
+func (e *rarEntry) Length() int64 { return e.info.length }
+
+// Reader implements Entry. It spools the entry to disk on first access so scanned pages
+// can be read out of order (e.g. by a positions service) without re-decoding the archive
+// from the start every time.
+func (e *rarEntry) Reader() (io.ReadCloser, error) {
+	tmp, err := e.archive.extract(e.info.path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(tmp)
+}
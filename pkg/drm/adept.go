@@ -0,0 +1,21 @@
+package drm
+
+import "github.com/pkg/errors"
+
+// ADEPTProtection detects Adobe ADEPT-protected publications (META-INF/rights.xml +
+// META-INF/encryption.xml). Unlike LCP, ADEPT's content key is wrapped with the user's
+// RSA activation private key rather than a passphrase-derived key, so decryption
+// requires an external activation (e.g. from an authorize.xml/DER key pair) that this
+// toolkit doesn't manage; Unlock reports that explicitly rather than pretending to
+// support a flow we can't complete.
+type ADEPTProtection struct {
+	RightsXML []byte
+}
+
+func (p ADEPTProtection) Scheme() Scheme {
+	return SchemeAdobeADEPT
+}
+
+func (p ADEPTProtection) Unlock(string) (Transform, error) {
+	return nil, errors.New("Adobe ADEPT decryption requires an external device activation and isn't implemented")
+}
@@ -0,0 +1,40 @@
+// Package drm detects and, where supported, decrypts the content-protection schemes a
+// publication may be wrapped in (Readium LCP, Adobe ADEPT), mirroring the ContentProtection
+// abstraction of the Kotlin and Swift Readium toolkits.
+package drm
+
+import "github.com/readium/go-toolkit/pkg/fetcher"
+
+// Scheme identifies a content-protection technology by its URI, as found in
+// META-INF/encryption.xml's <enc:EncryptionMethod>/<ds:KeyInfo> or a license document.
+type Scheme string
+
+const (
+	SchemeLCP        Scheme = "http://readium.org/2014/01/lcp"
+	SchemeAdobeADEPT Scheme = "http://ns.adobe.com/adept"
+)
+
+// Info describes the content protection detected on a publication, before it has been
+// unlocked with a passphrase or user key.
+type Info struct {
+	Scheme Scheme
+	// License is scheme-specific: the raw license.lcpl bytes for LCP, the rights.xml
+	// bytes for Adobe ADEPT.
+	License []byte
+}
+
+// Transform is a fetcher.Resource decorator, matching the signature expected by
+// fetcher.NewTransformingFetcher (see epub.NewDeobfuscator.Transform for the existing
+// font-obfuscation example of the same shape).
+type Transform func(fetcher.Resource) fetcher.Resource
+
+// ContentProtection unlocks a detected Info into a fetcher transform that decrypts
+// protected resources on the fly. Implementations are registered per Scheme; a caller
+// without the right passphrase/user key gets an error rather than garbled resources.
+type ContentProtection interface {
+	Scheme() Scheme
+	// Unlock derives the content key from a user-supplied passphrase (LCP) or user key
+	// (Adobe ADEPT activation) and returns a Transform that decrypts resources declared
+	// as encrypted under this scheme.
+	Unlock(passphrase string) (Transform, error)
+}
@@ -0,0 +1,143 @@
+package drm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/fetcher"
+)
+
+// License is the subset of a Readium LCP license document (license.lcpl) needed to
+// derive the content key. See https://readium.org/lcp-specs/releases/lcp/latest.html.
+type License struct {
+	ID         string `json:"id"`
+	Provider   string `json:"provider"`
+	Encryption struct {
+		Profile    string `json:"profile"`
+		ContentKey struct {
+			Algorithm      string `json:"algorithm"`
+			EncryptedValue string `json:"encrypted_value"` // base64
+		} `json:"content_key"`
+		UserKey struct {
+			Algorithm string `json:"algorithm"`
+			TextHint  string `json:"text_hint"`
+			KeyCheck  string `json:"key_check"` // base64
+		} `json:"user_key"`
+	} `json:"encryption"`
+}
+
+// ParseLicense decodes a license.lcpl document.
+func ParseLicense(data []byte) (*License, error) {
+	var license License
+	if err := json.Unmarshal(data, &license); err != nil {
+		return nil, errors.Wrap(err, "invalid LCP license document")
+	}
+	return &license, nil
+}
+
+// LCPProtection implements ContentProtection for Readium LCP-protected publications. It
+// decrypts resources declared in META-INF/encryption.xml with the standard
+// user-passphrase -> user-key -> content-key chain and AES-256-CBC.
+type LCPProtection struct {
+	License    *License
+	Encryption map[string]ResourceEncryption // resource href -> its encryption.xml entry
+}
+
+// ResourceEncryption is the information needed to decrypt a single resource declared as
+// LCP-encrypted in META-INF/encryption.xml.
+type ResourceEncryption struct {
+	Algorithm      string // always AES-256-CBC for LCP
+	OriginalLength int64
+	Compression    string // "deflate" or "none"
+}
+
+func (p LCPProtection) Scheme() Scheme {
+	return SchemeLCP
+}
+
+// Unlock hashes passphrase with SHA-256 to obtain the candidate user key, validates it
+// against the license's key_check, and decrypts the content key so resources can be
+// streamed through Transform.
+func (p LCPProtection) Unlock(passphrase string) (Transform, error) {
+	userKey := sha256.Sum256([]byte(passphrase))
+
+	keyCheck, err := base64.StdEncoding.DecodeString(p.License.Encryption.UserKey.KeyCheck)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid key_check in license")
+	}
+	decodedCheck, err := aesCBCDecrypt(userKey[:], keyCheck)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decrypting key_check")
+	}
+	if string(decodedCheck) != p.License.ID {
+		return nil, errors.New("incorrect passphrase")
+	}
+
+	encryptedContentKey, err := base64.StdEncoding.DecodeString(p.License.Encryption.ContentKey.EncryptedValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid content_key in license")
+	}
+	contentKey, err := aesCBCDecrypt(userKey[:], encryptedContentKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decrypting content key")
+	}
+
+	decryptor := contentKeyDecryptor{contentKey: contentKey, resources: p.Encryption}
+	return decryptor.Transform, nil
+}
+
+type contentKeyDecryptor struct {
+	contentKey []byte
+	resources  map[string]ResourceEncryption
+}
+
+// Transform decrypts a resource's bytes in place when it's declared as LCP-encrypted;
+// resources absent from encryption.xml (e.g. the license document itself) pass through
+// untouched.
+func (d contentKeyDecryptor) Transform(resource fetcher.Resource) fetcher.Resource {
+	encryption, ok := d.resources[resource.Link().Href.String()]
+	if !ok {
+		return resource
+	}
+	return newDecryptingResource(resource, d.contentKey, encryption)
+}
+
+// aesCBCDecrypt decrypts data with a 128-bit IV prepended to the ciphertext (the LCP
+// convention for key_check and content_key), stripping the trailing PKCS#7 padding.
+func aesCBCDecrypt(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("ciphertext is not a multiple of the AES block size")
+	}
+	iv, ciphertext := data[:aes.BlockSize], data[aes.BlockSize:]
+	if len(ciphertext) == 0 {
+		return nil, errors.New("empty ciphertext")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	return unpadPKCS7(plaintext)
+}
+
+func unpadPKCS7(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("cannot unpad empty data")
+	}
+	padLen := int(data[len(data)-1])
+	if padLen == 0 || padLen > len(data) || padLen > aes.BlockSize {
+		return nil, errors.New("invalid PKCS#7 padding")
+	}
+	if !bytes.Equal(data[len(data)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		return nil, errors.New("invalid PKCS#7 padding")
+	}
+	return data[:len(data)-padLen], nil
+}
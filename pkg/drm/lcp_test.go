@@ -0,0 +1,118 @@
+package drm
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func encryptAESCBC(t *testing.T, key, plaintext []byte) []byte {
+	t.Helper()
+	padded := padPKCS7(plaintext, aes.BlockSize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher failed: %v", err)
+	}
+	iv := bytes.Repeat([]byte{0x01}, aes.BlockSize)
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+	return append(append([]byte{}, iv...), ciphertext...)
+}
+
+func padPKCS7(data []byte, blockSize int) []byte {
+	padLen := blockSize - len(data)%blockSize
+	return append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+}
+
+func TestAESCBCDecryptRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	want := []byte("the quick brown fox")
+	data := encryptAESCBC(t, key, want)
+
+	got, err := aesCBCDecrypt(key, data)
+	if err != nil {
+		t.Fatalf("aesCBCDecrypt failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("aesCBCDecrypt() = %q, want %q", got, want)
+	}
+}
+
+func TestAESCBCDecryptRejectsShortOrMisalignedData(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	if _, err := aesCBCDecrypt(key, []byte("short")); err == nil {
+		t.Error("aesCBCDecrypt should reject data shorter than one AES block")
+	}
+	if _, err := aesCBCDecrypt(key, bytes.Repeat([]byte{0}, aes.BlockSize+1)); err == nil {
+		t.Error("aesCBCDecrypt should reject data not a multiple of the AES block size")
+	}
+}
+
+func TestUnpadPKCS7(t *testing.T) {
+	data := append([]byte("hello"), 3, 3, 3)
+	got, err := unpadPKCS7(data)
+	if err != nil {
+		t.Fatalf("unpadPKCS7 failed: %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("unpadPKCS7() = %q, want %q", got, "hello")
+	}
+}
+
+func TestUnpadPKCS7RejectsInvalidPadding(t *testing.T) {
+	cases := [][]byte{
+		{},
+		append([]byte("hello"), 0),
+		append([]byte("hello"), 1, 2),
+		bytes.Repeat([]byte{0}, aes.BlockSize+1),
+	}
+	for i, data := range cases {
+		if _, err := unpadPKCS7(data); err == nil {
+			t.Errorf("case %d: unpadPKCS7(%v) should have failed", i, data)
+		}
+	}
+}
+
+func TestInflate(t *testing.T) {
+	want := []byte("the quick brown fox jumps over the lazy dog")
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		t.Fatalf("flate.NewWriter failed: %v", err)
+	}
+	if _, err := fw.Write(want); err != nil {
+		t.Fatalf("flate write failed: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("flate close failed: %v", err)
+	}
+
+	got, err := inflate(buf.Bytes())
+	if err != nil {
+		t.Fatalf("inflate failed: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("inflate() = %q, want %q", got, want)
+	}
+}
+
+func TestInflateRejectsTruncatedInput(t *testing.T) {
+	var buf bytes.Buffer
+	fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		t.Fatalf("flate.NewWriter failed: %v", err)
+	}
+	if _, err := fw.Write([]byte("the quick brown fox jumps over the lazy dog")); err != nil {
+		t.Fatalf("flate write failed: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("flate close failed: %v", err)
+	}
+
+	truncated := buf.Bytes()[:buf.Len()/2]
+	if _, err := inflate(truncated); err == nil {
+		t.Error("inflate should have failed on a truncated DEFLATE stream")
+	}
+}
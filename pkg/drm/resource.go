@@ -0,0 +1,85 @@
+package drm
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/fetcher"
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+// decryptingResource wraps a fetcher.Resource whose underlying bytes are AES-256-CBC
+// encrypted under contentKey. Decryption happens once, on first access, since LCP
+// requires the full ciphertext to locate the final block's padding; the plaintext is
+// then served like any other resource. When encryption.Compression is "deflate" (the
+// LCP convention for text/CSS resources, which are deflated before encryption), the
+// decrypted bytes are inflated before being handed to the caller.
+type decryptingResource struct {
+	fetcher.Resource
+	contentKey []byte
+	encryption ResourceEncryption
+
+	plaintext []byte
+	err       error
+	decoded   bool
+}
+
+func newDecryptingResource(wrapped fetcher.Resource, contentKey []byte, encryption ResourceEncryption) fetcher.Resource {
+	return &decryptingResource{Resource: wrapped, contentKey: contentKey, encryption: encryption}
+}
+
+func (r *decryptingResource) Link() manifest.Link {
+	return r.Resource.Link()
+}
+
+// Length reports encryption.xml's declared OriginalLength (the decrypted size) instead
+// of deferring to the wrapped resource, whose Length() is the ciphertext's and, for
+// deflated resources, not even that: it's smaller than the final plaintext.
+func (r *decryptingResource) Length() (int64, error) {
+	return r.encryption.OriginalLength, nil
+}
+
+func (r *decryptingResource) Read() ([]byte, error) {
+	r.decodeOnce()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.plaintext, nil
+}
+
+func (r *decryptingResource) decodeOnce() {
+	if r.decoded {
+		return
+	}
+	r.decoded = true
+
+	ciphertext, err := r.Resource.Read()
+	if err != nil {
+		r.err = err
+		return
+	}
+	decrypted, err := aesCBCDecrypt(r.contentKey, ciphertext)
+	if err != nil {
+		r.err = err
+		return
+	}
+	if r.encryption.Compression != "deflate" {
+		r.plaintext = decrypted
+		return
+	}
+	r.plaintext, r.err = inflate(decrypted)
+}
+
+// inflate decompresses data as raw DEFLATE (no zlib/gzip header), the format LCP
+// resources are compressed with before encryption.
+func inflate(data []byte) ([]byte, error) {
+	fr := flate.NewReader(bytes.NewReader(data))
+	defer fr.Close()
+	out, err := io.ReadAll(fr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed inflating resource")
+	}
+	return out, nil
+}
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CachingEnricher wraps an Enricher with an in-memory LRU in front of a disk cache, both
+// keyed by ISBN, or by a hash of title and authors when there is no ISBN; OCLC numbers
+// are not used as keys. A lookup that misses memory but hits disk is promoted back into
+// memory; a lookup that misses both falls through to the wrapped Enricher and is written
+// to both layers.
+type CachingEnricher struct {
+	Enricher Enricher
+	Dir      string // disk cache directory; empty disables the disk layer
+
+	mu    sync.Mutex
+	lru   *list.List
+	index map[string]*list.Element
+	cap   int
+}
+
+type cacheEntry struct {
+	key   string
+	value Enriched
+}
+
+// NewCachingEnricher wraps enricher with an LRU of the given capacity, spilling to dir on
+// disk (dir == "" keeps the cache in memory only).
+func NewCachingEnricher(enricher Enricher, dir string, capacity int) *CachingEnricher {
+	if capacity <= 0 {
+		capacity = 256
+	}
+	return &CachingEnricher{
+		Enricher: enricher,
+		Dir:      dir,
+		lru:      list.New(),
+		index:    make(map[string]*list.Element),
+		cap:      capacity,
+	}
+}
+
+func (c *CachingEnricher) Name() string {
+	return c.Enricher.Name()
+}
+
+func (c *CachingEnricher) Enrich(isbn, title string, authors []string) (Enriched, error) {
+	key := cacheKey(isbn, title, authors)
+	if key == "" {
+		return c.Enricher.Enrich(isbn, title, authors)
+	}
+
+	if enriched, ok := c.getMemory(key); ok {
+		return enriched, nil
+	}
+	if enriched, ok := c.getDisk(key); ok {
+		c.putMemory(key, enriched)
+		return enriched, nil
+	}
+
+	enriched, err := c.Enricher.Enrich(isbn, title, authors)
+	if err != nil {
+		return Enriched{}, err
+	}
+	c.putMemory(key, enriched)
+	c.putDisk(key, enriched)
+	return enriched, nil
+}
+
+func (c *CachingEnricher) getMemory(key string) (Enriched, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.index[key]
+	if !ok {
+		return Enriched{}, false
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(cacheEntry).value, true
+}
+
+func (c *CachingEnricher) putMemory(key string, value Enriched) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.index[key]; ok {
+		el.Value = cacheEntry{key: key, value: value}
+		c.lru.MoveToFront(el)
+		return
+	}
+	el := c.lru.PushFront(cacheEntry{key: key, value: value})
+	c.index[key] = el
+	if c.lru.Len() > c.cap {
+		oldest := c.lru.Back()
+		if oldest != nil {
+			c.lru.Remove(oldest)
+			delete(c.index, oldest.Value.(cacheEntry).key)
+		}
+	}
+}
+
+func (c *CachingEnricher) getDisk(key string) (Enriched, bool) {
+	if c.Dir == "" {
+		return Enriched{}, false
+	}
+	data, err := os.ReadFile(c.cachePath(key))
+	if err != nil {
+		return Enriched{}, false
+	}
+	var enriched Enriched
+	if err := json.Unmarshal(data, &enriched); err != nil {
+		return Enriched{}, false
+	}
+	return enriched, true
+}
+
+func (c *CachingEnricher) putDisk(key string, value Enriched) {
+	if c.Dir == "" {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath(key), data, 0o644)
+}
+
+func (c *CachingEnricher) cachePath(key string) string {
+	// Prefixed with the provider name so several providers sharing one Dir (as in
+	// DefaultConfig) never read each other's cached results.
+	return filepath.Join(c.Dir, sanitizeCacheKey(c.Enricher.Name()+"-"+key)+".json")
+}
+
+// cacheKey derives the key CachingEnricher caches under: the ISBN when known, or else a
+// hash of title+authors so distinct titles never collide after sanitizeCacheKey strips
+// punctuation (e.g. "Foo:Bar" and "Foo/Bar" would otherwise both sanitize to the same
+// disk path).
+func cacheKey(isbn, title string, authors []string) string {
+	if isbn != "" {
+		return isbn
+	}
+	if title == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(title + "\x00" + strings.Join(authors, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func sanitizeCacheKey(key string) string {
+	out := make([]rune, 0, len(key))
+	for _, r := range key {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			out = append(out, r)
+		} else {
+			out = append(out, '_')
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,25 @@
+package metadata
+
+import "testing"
+
+func TestCacheKeyAvoidsTitleCollisions(t *testing.T) {
+	// Both titles sanitize to the same "Foo_Bar" once sanitizeCacheKey strips
+	// punctuation, so the cache key itself must already disambiguate them.
+	a := cacheKey("", "Foo:Bar", nil)
+	b := cacheKey("", "Foo/Bar", nil)
+	if a == b {
+		t.Fatalf("cacheKey collided for distinct titles: %q == %q", a, b)
+	}
+}
+
+func TestCacheKeyPrefersISBN(t *testing.T) {
+	if got := cacheKey("9780000000000", "Title", []string{"Author"}); got != "9780000000000" {
+		t.Errorf("cacheKey with ISBN = %q, want the ISBN unchanged", got)
+	}
+}
+
+func TestCacheKeyEmptyWithoutISBNOrTitle(t *testing.T) {
+	if got := cacheKey("", "", nil); got != "" {
+		t.Errorf("cacheKey() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,47 @@
+package metadata
+
+// Mode selects whether enrichment is allowed to reach out to the network.
+type Mode int
+
+const (
+	// Online allows providers to perform network lookups.
+	Online Mode = iota
+	// Offline skips enrichment entirely; Config.Enrich becomes a no-op returning
+	// Enriched{}, so parsers can unconditionally call it without a feature flag of
+	// their own.
+	Offline
+)
+
+// Config is what callers pass into a parser to configure metadata enrichment: which
+// providers to query, in what order, with how much caching, and whether to run at all.
+type Config struct {
+	Mode      Mode
+	Providers []Enricher
+}
+
+// DefaultConfig enriches via Google Books then OpenLibrary, each wrapped in a disk+LRU
+// cache under cacheDir (cacheDir == "" keeps the cache in memory only).
+func DefaultConfig(cacheDir string) Config {
+	return Config{
+		Mode: Online,
+		Providers: []Enricher{
+			NewCachingEnricher(NewGoogleBooksProvider(""), cacheDir, 256),
+			NewCachingEnricher(NewOpenLibraryProvider(), cacheDir, 256),
+		},
+	}
+}
+
+// WithProvider registers an additional provider (e.g. Hardcover, a private ONIX feed) to
+// try after the configured ones, without the caller having to rebuild the whole chain.
+func (c Config) WithProvider(provider Enricher) Config {
+	c.Providers = append(append([]Enricher{}, c.Providers...), provider)
+	return c
+}
+
+// Enrich runs the configured provider chain, or does nothing in Offline mode.
+func (c Config) Enrich(isbn, title string, authors []string) (Enriched, error) {
+	if c.Mode == Offline || len(c.Providers) == 0 {
+		return Enriched{}, nil
+	}
+	return Chain{Providers: c.Providers}.Enrich(isbn, title, authors)
+}
@@ -0,0 +1,99 @@
+// Package metadata augments a publication's parsed manifest.Metadata with fields that
+// aren't embedded in the file itself (description, subjects, cover URL, ...) by querying
+// external bibliographic sources, keyed by the book's ISBN.
+package metadata
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+var errInvalidDate = errors.New("unrecognized publication date format")
+
+// Enriched is what a provider adds on top of the fields already present in a parsed
+// manifest.Metadata. Every field is optional: a provider sets only what it found.
+type Enriched struct {
+	Description     string
+	Subjects        []string
+	PublicationDate string
+	Series          string
+	CoverURL        string
+	PageCount       int
+}
+
+func (e Enriched) isZero() bool {
+	return e.Description == "" && len(e.Subjects) == 0 && e.PublicationDate == "" &&
+		e.Series == "" && e.CoverURL == "" && e.PageCount == 0
+}
+
+// Enricher looks up supplementary metadata for a publication identified by isbn (ISO
+// 2108) or, failing that, by title/authors. It returns the zero Enriched and no error
+// when it simply has no match, so callers can fall through to the next provider.
+type Enricher interface {
+	Name() string
+	Enrich(isbn, title string, authors []string) (Enriched, error)
+}
+
+// Chain tries Providers in order and stops at the first one that finds a match, merging
+// nothing across providers: once one answers, it's authoritative for this lookup.
+type Chain struct {
+	Providers []Enricher
+}
+
+func (c Chain) Enrich(isbn, title string, authors []string) (Enriched, error) {
+	var lastErr error
+	for _, provider := range c.Providers {
+		enriched, err := provider.Enrich(isbn, title, authors)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !enriched.isZero() {
+			return enriched, nil
+		}
+	}
+	return Enriched{}, lastErr
+}
+
+// Apply fills in only the manifest.Metadata fields that are still empty, so an enricher
+// never overrides what the publication already declared for itself.
+func Apply(m *manifest.Metadata, enriched Enriched) {
+	if m.Description == "" {
+		m.Description = enriched.Description
+	}
+	if len(m.Subject) == 0 {
+		for _, subject := range enriched.Subjects {
+			m.Subject = append(m.Subject, manifest.Subject{LocalizedName: manifest.NewLocalizedStringFromString(subject)})
+		}
+	}
+	if m.Published == nil && enriched.PublicationDate != "" {
+		if t, err := parseFlexibleDate(enriched.PublicationDate); err == nil {
+			m.Published = &t
+		}
+	}
+	if m.Other == nil {
+		m.Other = make(map[string]interface{})
+	}
+	if _, ok := m.Other["series"]; !ok && enriched.Series != "" {
+		m.Other["series"] = enriched.Series
+	}
+	if _, ok := m.Other["coverUrl"]; !ok && enriched.CoverURL != "" {
+		m.Other["coverUrl"] = enriched.CoverURL
+	}
+	if _, ok := m.Other["pageCount"]; !ok && enriched.PageCount > 0 {
+		m.Other["pageCount"] = enriched.PageCount
+	}
+}
+
+// parseFlexibleDate parses the publication-date formats providers commonly return:
+// a full date, a year-month, or just a year.
+func parseFlexibleDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errInvalidDate
+}
@@ -0,0 +1,45 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+func TestApplyDoesNotOverrideExistingOtherFields(t *testing.T) {
+	m := &manifest.Metadata{
+		Other: map[string]interface{}{
+			"series":    "Already Set",
+			"coverUrl":  "https://example.com/already-set.jpg",
+			"pageCount": 42,
+		},
+	}
+
+	Apply(m, Enriched{Series: "From Provider", CoverURL: "https://example.com/provider.jpg", PageCount: 7})
+
+	if got := m.Other["series"]; got != "Already Set" {
+		t.Errorf("Other[series] = %v, want unchanged %q", got, "Already Set")
+	}
+	if got := m.Other["coverUrl"]; got != "https://example.com/already-set.jpg" {
+		t.Errorf("Other[coverUrl] = %v, want unchanged", got)
+	}
+	if got := m.Other["pageCount"]; got != 42 {
+		t.Errorf("Other[pageCount] = %v, want unchanged 42", got)
+	}
+}
+
+func TestApplyFillsEmptyOtherFields(t *testing.T) {
+	m := &manifest.Metadata{}
+
+	Apply(m, Enriched{Series: "From Provider", CoverURL: "https://example.com/provider.jpg", PageCount: 7})
+
+	if got := m.Other["series"]; got != "From Provider" {
+		t.Errorf("Other[series] = %v, want %q", got, "From Provider")
+	}
+	if got := m.Other["coverUrl"]; got != "https://example.com/provider.jpg" {
+		t.Errorf("Other[coverUrl] = %v, want provider value", got)
+	}
+	if got := m.Other["pageCount"]; got != 7 {
+		t.Errorf("Other[pageCount] = %v, want 7", got)
+	}
+}
@@ -0,0 +1,98 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GoogleBooksProvider queries the Google Books Volumes API
+// (https://developers.google.com/books/docs/v1/using#WorkingVolumes).
+type GoogleBooksProvider struct {
+	APIKey string // optional; requests are unauthenticated without it, at a lower quota
+	Client *http.Client
+}
+
+func NewGoogleBooksProvider(apiKey string) GoogleBooksProvider {
+	return GoogleBooksProvider{APIKey: apiKey, Client: http.DefaultClient}
+}
+
+func (p GoogleBooksProvider) Name() string {
+	return "google-books"
+}
+
+type googleBooksResponse struct {
+	Items []struct {
+		VolumeInfo struct {
+			Description   string   `json:"description"`
+			Categories    []string `json:"categories"`
+			PublishedDate string   `json:"publishedDate"`
+			PageCount     int      `json:"pageCount"`
+			ImageLinks    struct {
+				Thumbnail string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func (p GoogleBooksProvider) Enrich(isbn, title string, authors []string) (Enriched, error) {
+	query := "isbn:" + isbn
+	if isbn == "" {
+		query = buildTitleAuthorQuery(title, authors)
+	}
+	if query == "" {
+		return Enriched{}, nil
+	}
+
+	endpoint := "https://www.googleapis.com/books/v1/volumes?q=" + url.QueryEscape(query)
+	if p.APIKey != "" {
+		endpoint += "&key=" + url.QueryEscape(p.APIKey)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Enriched{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Enriched{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Enriched{}, fmt.Errorf("google books: unexpected status %d", resp.StatusCode)
+	}
+
+	var parsed googleBooksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Enriched{}, err
+	}
+	if len(parsed.Items) == 0 {
+		return Enriched{}, nil
+	}
+
+	info := parsed.Items[0].VolumeInfo
+	return Enriched{
+		Description:     info.Description,
+		Subjects:        info.Categories,
+		PublicationDate: info.PublishedDate,
+		CoverURL:        info.ImageLinks.Thumbnail,
+		PageCount:       info.PageCount,
+	}, nil
+}
+
+func buildTitleAuthorQuery(title string, authors []string) string {
+	if title == "" {
+		return ""
+	}
+	parts := []string{"intitle:" + title}
+	for _, author := range authors {
+		parts = append(parts, "inauthor:"+author)
+	}
+	return strings.Join(parts, "+")
+}
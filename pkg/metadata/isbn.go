@@ -0,0 +1,23 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+)
+
+var isbnDigits = regexp.MustCompile(`^(97[89])?\d{9}[\dXx]$`)
+
+// ISBNFromIdentifier pulls a plain ISBN-10/13 out of a dc:identifier-style value, which
+// is commonly a bare ISBN, a "urn:isbn:..." URN, or an unrelated scheme (UUID, DOI...)
+// that enrichment simply can't use. It returns "" when identifier isn't an ISBN.
+func ISBNFromIdentifier(identifier string) string {
+	candidate := identifier
+	if idx := strings.LastIndex(identifier, ":"); idx != -1 {
+		candidate = identifier[idx+1:]
+	}
+	candidate = strings.ReplaceAll(candidate, "-", "")
+	if isbnDigits.MatchString(candidate) {
+		return candidate
+	}
+	return ""
+}
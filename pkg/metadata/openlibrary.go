@@ -0,0 +1,91 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OpenLibraryProvider queries the OpenLibrary Books API
+// (https://openlibrary.org/dev/docs/api/books), which is looked up by ISBN only.
+type OpenLibraryProvider struct {
+	Client *http.Client
+}
+
+func NewOpenLibraryProvider() OpenLibraryProvider {
+	return OpenLibraryProvider{Client: http.DefaultClient}
+}
+
+func (p OpenLibraryProvider) Name() string {
+	return "open-library"
+}
+
+type openLibraryBook struct {
+	Excerpts []struct {
+		Text string `json:"text"`
+	} `json:"excerpts"`
+	Subjects []struct {
+		Name string `json:"name"`
+	} `json:"subjects"`
+	PublishDate   string `json:"publish_date"`
+	NumberOfPages int    `json:"number_of_pages"`
+	Cover         struct {
+		Medium string `json:"medium"`
+	} `json:"cover"`
+}
+
+func (p OpenLibraryProvider) Enrich(isbn, title string, authors []string) (Enriched, error) {
+	if isbn == "" {
+		return Enriched{}, nil
+	}
+
+	key := "ISBN:" + isbn
+	endpoint := fmt.Sprintf(
+		"https://openlibrary.org/api/books?bibkeys=%s&format=json&jscmd=details",
+		url.QueryEscape(key),
+	)
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return Enriched{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Enriched{}, fmt.Errorf("open library: unexpected status %d", resp.StatusCode)
+	}
+
+	var parsed map[string]struct {
+		Details openLibraryBook `json:"details"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Enriched{}, err
+	}
+
+	entry, ok := parsed[key]
+	if !ok {
+		return Enriched{}, nil
+	}
+	details := entry.Details
+
+	var description string
+	if len(details.Excerpts) > 0 {
+		description = details.Excerpts[0].Text
+	}
+	subjects := make([]string, 0, len(details.Subjects))
+	for _, s := range details.Subjects {
+		subjects = append(subjects, s.Name)
+	}
+
+	return Enriched{
+		Description:     description,
+		Subjects:        subjects,
+		PublicationDate: details.PublishDate,
+		PageCount:       details.NumberOfPages,
+		CoverURL:        details.Cover.Medium,
+	}, nil
+}
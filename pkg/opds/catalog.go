@@ -0,0 +1,180 @@
+package opds
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/asset"
+	"github.com/readium/go-toolkit/pkg/fetcher"
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/pub"
+)
+
+// PublicationProvider lists the assets a Catalog should serve. DirectoryProvider is the
+// default; callers that keep publications elsewhere (a database, object storage) can
+// implement their own.
+type PublicationProvider interface {
+	Publications() ([]asset.PublicationAsset, error)
+}
+
+// DirectoryProvider lists every file directly readable as a publication asset under Root.
+type DirectoryProvider struct {
+	Root string
+}
+
+func (p DirectoryProvider) Publications() ([]asset.PublicationAsset, error) {
+	var assets []asset.PublicationAsset
+	err := filepath.Walk(p.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		a, err := asset.NewFileAsset(path)
+		if err != nil {
+			return nil // not a file we can make an asset out of, skip
+		}
+		assets = append(assets, a)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed walking publication directory")
+	}
+	return assets, nil
+}
+
+// Catalog walks a PublicationProvider, parses each asset through the registered
+// PublicationParsers, and renders the result as an OPDS 2.0 feed.
+type Catalog struct {
+	Title    string
+	SelfHref string
+	Provider PublicationProvider
+	Parsers  []pub.PublicationParser
+	Covers   CoverService
+}
+
+// NewCatalog builds a Catalog over the given directory, trying parsers on each asset in
+// the order given and keeping the first one that succeeds.
+func NewCatalog(title, selfHref, root string, parsers []pub.PublicationParser) Catalog {
+	return Catalog{
+		Title:    title,
+		SelfHref: selfHref,
+		Provider: DirectoryProvider{Root: root},
+		Parsers:  parsers,
+		Covers:   CoverService{},
+	}
+}
+
+type cataloged struct {
+	asset    asset.PublicationAsset
+	manifest manifest.Manifest
+}
+
+// Generate parses every publication the provider returns and renders page of the result
+// as an OPDS 2.0 Feed. page is 1-based; pageSize <= 0 means "no pagination".
+func (c Catalog) Generate(page, pageSize int, query string) (*Feed, error) {
+	assets, err := c.Provider.Publications()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]cataloged, 0, len(assets))
+	for _, a := range assets {
+		m, ok := c.parse(a)
+		if !ok {
+			continue
+		}
+		if query != "" && !matchesQuery(m.Metadata, query) {
+			continue
+		}
+		entries = append(entries, cataloged{asset: a, manifest: m})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].manifest.Metadata.LocalizedTitle.String() < entries[j].manifest.Metadata.LocalizedTitle.String()
+	})
+
+	total := len(entries)
+	pageEntries := paginate(entries, page, pageSize)
+
+	feed := &Feed{
+		Context: manifest.Strings{"https://readium.org/webpub-manifest/context.jsonld"},
+		Metadata: FeedMetadata{
+			Title:         c.Title,
+			NumberOfItems: total,
+		},
+		Links: manifest.LinkList{
+			{Href: manifest.MustNewHREFFromString(c.SelfHref, false), Rels: []string{"self"}, MediaType: mustMediaType(MediaTypeOPDS2)},
+			{Href: manifest.MustNewHREFFromString(c.SelfHref+"/search", false), Rels: []string{"search"}, MediaType: mustMediaType("application/opensearchdescription+xml")},
+		},
+		Facets:       buildFacets(entries),
+		Publications: make([]PublicationEntry, 0, len(pageEntries)),
+	}
+	if pageSize > 0 {
+		feed.Metadata.ItemsPerPage = pageSize
+		feed.Metadata.CurrentPage = page
+		feed.Links = append(feed.Links, paginationLinks(c.SelfHref, page, pageSize, total)...)
+	}
+
+	for _, e := range pageEntries {
+		feed.Publications = append(feed.Publications, c.toEntry(e))
+	}
+
+	return feed, nil
+}
+
+func (c Catalog) parse(a asset.PublicationAsset) (manifest.Manifest, bool) {
+	f, err := fetcher.NewFileFetcher(a)
+	if err != nil {
+		return manifest.Manifest{}, false
+	}
+	defer f.Close()
+
+	for _, parser := range c.Parsers {
+		builder, err := parser.Parse(a, f)
+		if err != nil || builder == nil {
+			continue
+		}
+		p := builder.Build()
+		return p.Manifest, true
+	}
+	return manifest.Manifest{}, false
+}
+
+func (c Catalog) toEntry(e cataloged) PublicationEntry {
+	name := url.PathEscape(e.asset.Name())
+	entry := PublicationEntry{
+		Metadata: e.manifest.Metadata,
+		Links: manifest.LinkList{
+			{Href: manifest.MustNewHREFFromString("/publications/"+name+"/manifest.json", false), Rels: []string{"self"}, MediaType: mustMediaType("application/webpub+json")},
+			{Href: manifest.MustNewHREFFromString("/publications/"+name+"/asset", false), Rels: []string{"http://opds-spec.org/acquisition"}, MediaType: e.asset.MediaType()},
+		},
+	}
+	if cover, ok := c.Covers.Thumbnail(e.manifest.ReadingOrder); ok {
+		// Thumbnail's Href is a path inside the publication's own archive (e.g.
+		// "images/cover.jpg"), meaningless outside it; rewrite it to the catalog route
+		// that serves this publication's cover, the same way the self/acquisition links
+		// above are rooted at "/publications/"+name rather than an internal path.
+		cover.Href = manifest.MustNewHREFFromString("/publications/"+name+"/cover", false)
+		entry.Images = manifest.LinkList{cover}
+	}
+	return entry
+}
+
+func matchesQuery(m manifest.Metadata, query string) bool {
+	query = strings.ToLower(query)
+	if strings.Contains(strings.ToLower(m.LocalizedTitle.String()), query) {
+		return true
+	}
+	for _, author := range m.Author {
+		if strings.Contains(strings.ToLower(author.LocalizedName.String()), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package opds
+
+import "github.com/readium/go-toolkit/pkg/manifest"
+
+// CoverService picks a thumbnail link out of an already-built publication's reading
+// order, so it benefits from whatever cover selection the publication's own parser
+// already did (e.g. ImageParser's ACBF-resolved cover rel) instead of re-deriving one
+// from the raw asset.
+type CoverService struct{}
+
+// Thumbnail returns a Link pointing at readingOrder's cover resource, if one can be
+// found: the first link tagged rel=cover, or failing that, the first bitmap link.
+func (s CoverService) Thumbnail(readingOrder manifest.LinkList) (manifest.Link, bool) {
+	var firstBitmap *manifest.Link
+	for i := range readingOrder {
+		link := readingOrder[i]
+		if !link.MediaType.IsBitmap() {
+			continue
+		}
+		if firstBitmap == nil {
+			firstBitmap = &link
+		}
+		for _, rel := range link.Rels {
+			if rel == "cover" {
+				return link, true
+			}
+		}
+	}
+	if firstBitmap != nil {
+		return *firstBitmap, true
+	}
+	return manifest.Link{}, false
+}
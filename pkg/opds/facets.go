@@ -0,0 +1,69 @@
+package opds
+
+import (
+	"sort"
+
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+// buildFacets groups entries by language, subject and author, mirroring the facets most
+// Readium-compatible clients expect on an OPDS 2.0 feed.
+func buildFacets(entries []cataloged) []Facet {
+	languages := make(map[string]int)
+	subjects := make(map[string]int)
+	authors := make(map[string]int)
+
+	for _, e := range entries {
+		for _, lang := range e.manifest.Metadata.Language {
+			languages[lang]++
+		}
+		for _, subject := range e.manifest.Metadata.Subject {
+			subjects[subject.LocalizedName.String()]++
+		}
+		for _, author := range e.manifest.Metadata.Author {
+			authors[author.LocalizedName.String()]++
+		}
+	}
+
+	facets := []Facet{
+		facetFromCounts("Languages", "language", languages),
+		facetFromCounts("Subjects", "subject", subjects),
+		facetFromCounts("Authors", "author", authors),
+	}
+
+	out := facets[:0]
+	for _, f := range facets {
+		if len(f.Links) > 0 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func facetFromCounts(title, param string, counts map[string]int) Facet {
+	facet := Facet{Metadata: FacetMetadata{Title: title, NumberOfItems: len(counts)}}
+
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	for _, v := range values {
+		facet.Links = append(facet.Links, facetLink(title, param, v, counts[v]))
+	}
+	return facet
+}
+
+func facetLink(group, param, value string, count int) manifest.Link {
+	return manifest.Link{
+		Href:  manifest.MustNewHREFFromString("/?"+param+"="+value, false),
+		Title: value,
+		Properties: manifest.Properties{
+			Other: map[string]interface{}{
+				"numberOfItems": count,
+				"facetGroup":    group,
+			},
+		},
+	}
+}
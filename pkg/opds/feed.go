@@ -0,0 +1,45 @@
+// Package opds serves a directory of publications parsed by this toolkit's
+// PublicationParsers as an OPDS 2.0 catalog (https://drafts.opds.io/opds-2.0).
+package opds
+
+import "github.com/readium/go-toolkit/pkg/manifest"
+
+// MediaTypeOPDS2 is the content type of an OPDS 2.0 feed document.
+const MediaTypeOPDS2 = "application/opds+json"
+
+// Feed is the top-level OPDS 2.0 JSON document.
+type Feed struct {
+	Context      manifest.Strings   `json:"@context,omitempty"`
+	Metadata     FeedMetadata       `json:"metadata"`
+	Links        manifest.LinkList  `json:"links"`
+	Navigation   manifest.LinkList  `json:"navigation,omitempty"`
+	Facets       []Facet            `json:"facets,omitempty"`
+	Publications []PublicationEntry `json:"publications,omitempty"`
+}
+
+// FeedMetadata is the feed-level "metadata" object: title plus RFC 5005 pagination.
+type FeedMetadata struct {
+	Title         string `json:"title"`
+	ItemsPerPage  int    `json:"itemsPerPage,omitempty"`
+	CurrentPage   int    `json:"currentPage,omitempty"`
+	NumberOfItems int    `json:"numberOfItems,omitempty"`
+}
+
+// Facet is a named group of filter links, e.g. "Languages" or "Subjects".
+type Facet struct {
+	Metadata FacetMetadata     `json:"metadata"`
+	Links    manifest.LinkList `json:"links"`
+}
+
+type FacetMetadata struct {
+	Title         string `json:"title"`
+	NumberOfItems int    `json:"numberOfItems,omitempty"`
+}
+
+// PublicationEntry is one catalog entry: the publication's own metadata plus links to
+// its manifest, raw asset download, and cover thumbnail.
+type PublicationEntry struct {
+	Metadata manifest.Metadata `json:"metadata"`
+	Links    manifest.LinkList `json:"links"`
+	Images   manifest.LinkList `json:"images,omitempty"`
+}
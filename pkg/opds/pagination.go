@@ -0,0 +1,59 @@
+package opds
+
+import (
+	"fmt"
+
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/mediatype"
+)
+
+// paginate slices entries down to the requested page. page is 1-based; pageSize <= 0
+// disables pagination and returns every entry.
+func paginate(entries []cataloged, page, pageSize int) []cataloged {
+	if pageSize <= 0 {
+		return entries
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * pageSize
+	if start >= len(entries) {
+		return nil
+	}
+	end := start + pageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+	return entries[start:end]
+}
+
+// paginationLinks builds the RFC 5005 previous/next/first/last links for page pageSize
+// over a result set of total items.
+func paginationLinks(selfHref string, page, pageSize, total int) manifest.LinkList {
+	var links manifest.LinkList
+	lastPage := (total + pageSize - 1) / pageSize
+
+	add := func(rel string, targetPage int) {
+		links = append(links, manifest.Link{
+			Href:      manifest.MustNewHREFFromString(fmt.Sprintf("%s?page=%d&pageSize=%d", selfHref, targetPage, pageSize), false),
+			Rels:      []string{rel},
+			MediaType: mustMediaType(MediaTypeOPDS2),
+		})
+	}
+
+	add("first", 1)
+	if page > 1 {
+		add("previous", page-1)
+	}
+	if page < lastPage {
+		add("next", page+1)
+	}
+	if lastPage > 0 {
+		add("last", lastPage)
+	}
+	return links
+}
+
+func mustMediaType(s string) mediatype.MediaType {
+	return mediatype.MustNewOfString(s)
+}
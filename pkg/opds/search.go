@@ -0,0 +1,14 @@
+package opds
+
+import "fmt"
+
+// OpenSearchDescription renders the OpenSearch description document advertised by the
+// feed's rel=search link, so catalog clients can build a search URL from a user query.
+func (c Catalog) OpenSearchDescription() []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">
+  <ShortName>%s</ShortName>
+  <Description>Search %s</Description>
+  <Url type="%s" template="%s?query={searchTerms}"/>
+</OpenSearchDescription>`, c.Title, c.Title, MediaTypeOPDS2, c.SelfHref))
+}
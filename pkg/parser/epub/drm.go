@@ -0,0 +1,69 @@
+package epub
+
+import (
+	"github.com/readium/go-toolkit/pkg/drm"
+	"github.com/readium/go-toolkit/pkg/fetcher"
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/util/url"
+)
+
+// detectDRM looks for the two content-protection schemes this toolkit recognizes: a
+// Readium LCP license (META-INF/license.lcpl) or Adobe ADEPT rights (META-INF/rights.xml
+// alongside an encryption.xml). It returns nil when the publication isn't protected.
+func detectDRM(f fetcher.Fetcher, encryption map[url.URL]manifest.Encryption) drm.ContentProtection {
+	if data, err := f.Get(manifest.Link{Href: manifest.MustNewHREFFromString("META-INF/license.lcpl", false)}).Read(); err == nil {
+		if license, err := drm.ParseLicense(data); err == nil {
+			return drm.LCPProtection{
+				License:    license,
+				Encryption: lcpResourceEncryption(encryption),
+			}
+		}
+	}
+
+	if rightsXML, err := f.Get(manifest.Link{Href: manifest.MustNewHREFFromString("META-INF/rights.xml", false)}).Read(); err == nil && len(encryption) > 0 {
+		return drm.ADEPTProtection{RightsXML: rightsXML}
+	}
+
+	return nil
+}
+
+// lcpResourceEncryption narrows the full encryption.xml map down to the entries
+// encrypted under the LCP scheme, in the shape drm.LCPProtection needs to decide which
+// resources to pass through its Transform.
+func lcpResourceEncryption(encryption map[url.URL]manifest.Encryption) map[string]drm.ResourceEncryption {
+	out := make(map[string]drm.ResourceEncryption)
+	for href, enc := range encryption {
+		if enc.Scheme != string(drm.SchemeLCP) {
+			continue
+		}
+		out[href.String()] = drm.ResourceEncryption{
+			Algorithm:      enc.Algorithm,
+			OriginalLength: enc.OriginalLength,
+			Compression:    enc.Compression,
+		}
+	}
+	return out
+}
+
+// applyDRM records the detected scheme on the manifest and, if a passphrase was supplied
+// up front and unlocking succeeds, wraps f with a decrypting fetcher. A wrong or absent
+// passphrase still yields a usable publication: callers just can't read protected
+// resources until they retry with the right one.
+func applyDRM(m *manifest.Manifest, f fetcher.Fetcher, protection drm.ContentProtection, passphrase string) fetcher.Fetcher {
+	if protection == nil {
+		return f
+	}
+	if m.Metadata.Other == nil {
+		m.Metadata.Other = make(map[string]interface{})
+	}
+	m.Metadata.Other["drm"] = map[string]interface{}{"scheme": string(protection.Scheme())}
+
+	if passphrase == "" {
+		return f
+	}
+	transform, err := protection.Unlock(passphrase)
+	if err != nil {
+		return f
+	}
+	return fetcher.NewTransformingFetcher(f, transform)
+}
@@ -0,0 +1,26 @@
+package epub
+
+import (
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/metadata"
+)
+
+// enrich queries cfg for the fields a publication's own metadata is missing (description,
+// subjects, publication date, cover URL, page count) and merges them in, keyed by ISBN
+// when the dc:identifier is one, falling back to title/author search otherwise.
+func enrich(m *manifest.Metadata, cfg metadata.Config) {
+	if cfg.Mode == metadata.Offline || len(cfg.Providers) == 0 {
+		return
+	}
+
+	authors := make([]string, 0, len(m.Author))
+	for _, a := range m.Author {
+		authors = append(authors, a.LocalizedName.String())
+	}
+
+	enriched, err := cfg.Enrich(metadata.ISBNFromIdentifier(m.Identifier), m.LocalizedTitle.String(), authors)
+	if err != nil {
+		return
+	}
+	metadata.Apply(m, enriched)
+}
@@ -0,0 +1,187 @@
+package epub
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/readium/go-toolkit/pkg/fetcher"
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+// koboSpanClass is the class Kobo wraps every sentence in: <span class="koboSpan"
+// id="kobo.N.M">...</span>, where N is the paragraph index and M the sentence index
+// within it.
+const koboSpanClass = "koboSpan"
+
+var koboSpanIDPattern = regexp.MustCompile(`kobo\.\d+\.\d+`)
+
+// koboSpanTagPattern matches an opening <span ...> tag; see countKoboSpans.
+var koboSpanTagPattern = regexp.MustCompile(`(?i)<span\b[^>]*>`)
+
+// IsKEPUB reports whether an EPUB asset is Kobo's KEPUB variant: either its filename
+// ends in ".kepub.epub", or its content documents already carry koboSpan markers or a
+// com.kobobooks.display-options.xml sidecar.
+func IsKEPUB(assetName string, f fetcher.Fetcher) bool {
+	if strings.HasSuffix(strings.ToLower(assetName), ".kepub.epub") {
+		return true
+	}
+	if _, err := f.Get(manifest.Link{Href: manifest.MustNewHREFFromString("META-INF/com.kobobooks.display-options.xml", false)}).Read(); err == nil {
+		return true
+	}
+	links, err := f.Links()
+	if err != nil {
+		return false
+	}
+	for _, link := range links {
+		if !strings.HasSuffix(link.Href.String(), ".xhtml") && !strings.HasSuffix(link.Href.String(), ".html") {
+			continue
+		}
+		data, err := f.Get(link).Read()
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(data), koboSpanClass) {
+			return true
+		}
+	}
+	return false
+}
+
+// KoboPositionsStrategy counts koboSpan elements, rather than characters, as the unit of
+// a reading position, so locators line up with the "kobo.N.M" ids Kobo's own reading
+// apps use for progress reporting.
+var KoboPositionsStrategy ReflowableStrategy = koboPositionsStrategy{}
+
+type koboPositionsStrategy struct{}
+
+// Positions implements ReflowableStrategy by splitting a resource into one position per
+// koboSpan found in it, falling back to a single position for resources with none (e.g.
+// a cover page).
+func (koboPositionsStrategy) Positions(resource fetcher.Resource) (int, error) {
+	data, err := resource.Read()
+	if err != nil {
+		return 0, err
+	}
+	return countKoboSpans(data), nil
+}
+
+// countKoboSpans counts actual <span class="koboSpan" id="kobo.N.M"> markers, falling
+// back to 1 for a resource with none (e.g. a cover page). It scopes koboSpanIDPattern to
+// the inside of a <span> opening tag, so an anchor like href="#kobo.1.2" pointing at a
+// span doesn't also count as one.
+func countKoboSpans(data []byte) int {
+	classAttr := []byte(`class="` + koboSpanClass + `"`)
+	count := 0
+	for _, tag := range koboSpanTagPattern.FindAll(data, -1) {
+		if bytes.Contains(tag, classAttr) && koboSpanIDPattern.Match(tag) {
+			count++
+		}
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// NewKoboSpanInjector builds a fetcher transform suitable for fetcher.NewTransformingFetcher
+// that re-injects koboSpan wrappers on XHTML resources of a plain (non-KEPUB) EPUB. The
+// paragraph counter used to number spans starts fresh for each resource (matching real
+// Kobo-injected ids, which restart per content document), so the transform itself keeps
+// no mutable state: it's safe to call concurrently for different resources, e.g. from a
+// content or positions service walking pages in parallel.
+func NewKoboSpanInjector() func(fetcher.Resource) fetcher.Resource {
+	return koboSpanTransform
+}
+
+func koboSpanTransform(resource fetcher.Resource) fetcher.Resource {
+	href := resource.Link().Href.String()
+	if !strings.HasSuffix(href, ".xhtml") && !strings.HasSuffix(href, ".html") {
+		return resource
+	}
+	return &koboSpanResource{Resource: resource}
+}
+
+type koboSpanResource struct {
+	fetcher.Resource
+}
+
+func (r *koboSpanResource) Read() ([]byte, error) {
+	data, err := r.Resource.Read()
+	if err != nil {
+		return nil, err
+	}
+	return injectKoboSpans(data), nil
+}
+
+// sentenceBoundary is a coarse approximation of sentence breaks, splitting on
+// terminal punctuation followed by whitespace. Real Kobo firmware uses a proper
+// tokenizer; this is enough to produce a stable, well-formed span grid from plain text
+// runs between tags.
+var sentenceBoundary = regexp.MustCompile(`([.!?])(\s+)`)
+
+// rawTextOpenTag and rawTextCloseTag bracket <script> and <style> element bodies, whose
+// contents are CSS/JS, not prose, and must never get koboSpan markup injected into them.
+var (
+	rawTextOpenTag  = regexp.MustCompile(`(?i)^<(?:script|style)\b`)
+	rawTextCloseTag = regexp.MustCompile(`(?i)^</(?:script|style)\s*>`)
+)
+
+func injectKoboSpans(html []byte) []byte {
+	// Only wrap text nodes, not tags: split on tag boundaries and only touch the parts
+	// that aren't themselves a tag.
+	var out strings.Builder
+	text := string(html)
+	tagOrText := regexp.MustCompile(`(?s)(<[^>]*>)|([^<]+)`)
+
+	paragraph := 0
+	inRawText := false
+	for _, match := range tagOrText.FindAllStringSubmatch(text, -1) {
+		if match[1] != "" {
+			out.WriteString(match[1])
+			if !inRawText && rawTextOpenTag.MatchString(match[1]) {
+				inRawText = true
+			} else if inRawText && rawTextCloseTag.MatchString(match[1]) {
+				inRawText = false
+			}
+			continue
+		}
+		if inRawText {
+			out.WriteString(match[2])
+			continue
+		}
+		if strings.TrimSpace(match[2]) == "" {
+			out.WriteString(match[2])
+			continue
+		}
+		paragraph++
+		sentence := 0
+		// Cut after each boundary match (punctuation and trailing whitespace included), so
+		// the spans together hold exactly the original text.
+		run := match[2]
+		var parts []string
+		start := 0
+		for _, loc := range sentenceBoundary.FindAllStringIndex(run, -1) {
+			parts = append(parts, run[start:loc[1]])
+			start = loc[1]
+		}
+		parts = append(parts, run[start:])
+		for _, part := range parts {
+			if part == "" {
+				continue
+			}
+			out.WriteString(`<span class="`)
+			out.WriteString(koboSpanClass)
+			out.WriteString(`" id="kobo.`)
+			out.WriteString(strconv.Itoa(paragraph))
+			out.WriteString(".")
+			out.WriteString(strconv.Itoa(sentence))
+			out.WriteString(`">`)
+			out.WriteString(part)
+			out.WriteString(`</span>`)
+			sentence++
+		}
+	}
+	return []byte(out.String())
+}
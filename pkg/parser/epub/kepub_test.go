@@ -0,0 +1,55 @@
+package epub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestInjectKoboSpansSkipsScriptAndStyle(t *testing.T) {
+	html := []byte(`<html><head><style>body { content: "a.b.c > d"; }</style></head>` +
+		`<body><script>var x = "end. Of sentence.";</script><p>One sentence. Another one.</p></body></html>`)
+
+	got := injectKoboSpans(html)
+
+	if !bytes.Contains(got, []byte(`content: "a.b.c > d"`)) {
+		t.Errorf("injectKoboSpans corrupted <style> contents: %s", got)
+	}
+	if !bytes.Contains(got, []byte(`var x = "end. Of sentence.";`)) {
+		t.Errorf("injectKoboSpans corrupted <script> contents: %s", got)
+	}
+	if strings.Count(string(got), `class="koboSpan"`) != 2 {
+		t.Errorf("injectKoboSpans = %s, want exactly 2 koboSpan wrappers (one per sentence in <p>)", got)
+	}
+}
+
+func TestInjectKoboSpansResetsSentenceIndexPerParagraph(t *testing.T) {
+	html := []byte(`<p>First sentence. Second sentence.</p><p>Third sentence. Fourth sentence.</p>`)
+
+	got := injectKoboSpans(html)
+
+	for _, id := range []string{"kobo.1.0", "kobo.1.1", "kobo.2.0", "kobo.2.1"} {
+		if !bytes.Contains(got, []byte(`id="`+id+`"`)) {
+			t.Errorf("injectKoboSpans = %s, missing %q (sentence index must restart at each paragraph)", got, id)
+		}
+	}
+	if bytes.Contains(got, []byte(`id="kobo.2.2"`)) {
+		t.Errorf("injectKoboSpans = %s, sentence index carried over from the first paragraph", got)
+	}
+}
+
+func TestCountKoboSpansIgnoresAnchorBackreferences(t *testing.T) {
+	html := []byte(`<p><span class="koboSpan" id="kobo.1.0">First sentence.</span> ` +
+		`<a href="#kobo.5.0">footnote</a> ` +
+		`<span class="koboSpan" id="kobo.1.1">Second sentence.</span></p>`)
+
+	if n := countKoboSpans(html); n != 2 {
+		t.Errorf("countKoboSpans() = %d, want 2 (anchor backreference must not count as a span)", n)
+	}
+}
+
+func TestCountKoboSpansFallsBackToOne(t *testing.T) {
+	if n := countKoboSpans([]byte(`<p>no spans here</p>`)); n != 1 {
+		t.Errorf("countKoboSpans() = %d, want 1 for a resource with no koboSpan markers", n)
+	}
+}
@@ -0,0 +1,288 @@
+package epub
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/readium/go-toolkit/pkg/fetcher"
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/mediatype"
+	"github.com/readium/go-toolkit/pkg/pub"
+	"github.com/readium/go-toolkit/pkg/util/url"
+)
+
+// MediaOverlay is the synchronized text/audio playback range tree for a single spine
+// item, parsed from the SMIL document referenced by its manifest item's media-overlay
+// attribute.
+type MediaOverlay struct {
+	Nodes               []MediaOverlayNode
+	Duration            time.Duration // this SMIL's own media:duration, 0 if not declared
+	Narrator            string        // media:narrator
+	ActiveClass         string        // media:active-class
+	PlaybackActiveClass string        // media:playback-active-class
+}
+
+// MediaOverlayNode is a single <seq> or <par> element of a SMIL body. Seq nodes carry
+// Children and no Text/Audio; par nodes carry a Text fragment and, optionally, an Audio
+// clip and no children.
+type MediaOverlayNode struct {
+	Role     string // "seq" or "par"
+	Text     string // fragment href, e.g. "chapter1.xhtml#sentence3"
+	Audio    *AudioClip
+	Children []MediaOverlayNode
+}
+
+// AudioClip is a <audio> element's src and clip range, normalized to seconds.
+type AudioClip struct {
+	Href      string
+	ClipBegin float64
+	ClipEnd   float64
+	Skippable bool
+}
+
+// skippableStructureTypes are the epub:type structural-semantics values EPUB Media
+// Overlays 3.0 treats as skippable by default: footnotes/rearnotes and the in-text
+// references that point at them. A Reading System may still let the user toggle this,
+// but it must know which structures are skippable in the first place.
+var skippableStructureTypes = map[string]bool{
+	"noteref":     true,
+	"footnote":    true,
+	"rearnote":    true,
+	"rearnoteref": true,
+	"annotation":  true,
+	"annoref":     true,
+}
+
+// isSkippableStructure reports whether el's own epub:type declares one of the structural
+// semantics in skippableStructureTypes. epub:type is a space-separated list of tokens.
+func isSkippableStructure(el *xmlquery.Node) bool {
+	for _, t := range strings.Fields(el.SelectAttr("epub:type")) {
+		if skippableStructureTypes[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMediaOverlays reads the SMIL document for every manifest item that declares a
+// media-overlay, normalizes its sync narration tree, and keys the result by the spine
+// resource it narrates so GuidedNavigationService can look it up per-link.
+func parseMediaOverlays(packageDocument PackageDocument, f fetcher.Fetcher) map[url.URL]MediaOverlay {
+	overlays := make(map[url.URL]MediaOverlay)
+
+	narrator, activeClass, playbackActiveClass := parseMediaOverlayMetadata(packageDocument)
+
+	for _, item := range packageDocument.Manifest {
+		if item.MediaOverlay == "" {
+			continue
+		}
+		var smilItem *Item
+		for i := range packageDocument.Manifest {
+			if packageDocument.Manifest[i].ID == item.MediaOverlay {
+				smilItem = &packageDocument.Manifest[i]
+				break
+			}
+		}
+		if smilItem == nil {
+			continue
+		}
+
+		smilPath := packageDocument.Path.Resolve(smilItem.Href)
+		doc, err := f.Get(manifest.Link{Href: manifest.NewHREF(smilPath)}).ReadAsXML(map[string]string{
+			NamespaceSMIL: "smil",
+			NamespaceOPS:  "epub",
+		})
+		if err != nil {
+			continue
+		}
+
+		body := doc.SelectElement("//body")
+		if body == nil {
+			continue
+		}
+
+		itemPath := packageDocument.Path.Resolve(item.Href)
+		overlays[itemPath] = MediaOverlay{
+			Nodes:               parseSMILChildren(body, smilPath, false),
+			Duration:            parseSMILDuration(packageDocument, smilItem.ID),
+			Narrator:            narrator,
+			ActiveClass:         activeClass,
+			PlaybackActiveClass: playbackActiveClass,
+		}
+	}
+
+	return overlays
+}
+
+// parseSMILChildren walks parent's element children in document order (not grouped by
+// tag name) so the returned nodes preserve the actual playback sequence of a <body> or
+// <seq> that mixes <par> and <seq> siblings.
+func parseSMILChildren(parent *xmlquery.Node, base url.URL, skippable bool) []MediaOverlayNode {
+	var nodes []MediaOverlayNode
+	for el := parent.FirstChild; el != nil; el = el.NextSibling {
+		if el.Type != xmlquery.ElementNode {
+			continue
+		}
+		switch el.Data {
+		case "seq":
+			nodes = append(nodes, MediaOverlayNode{
+				Role:     "seq",
+				Children: parseSMILChildren(el, base, skippable || isSkippableStructure(el)),
+			})
+		case "par":
+			node := MediaOverlayNode{Role: "par"}
+			parSkippable := skippable || isSkippableStructure(el)
+			if text := el.SelectElement("text"); text != nil {
+				node.Text = resolveFragment(base, text.SelectAttr("src"))
+			}
+			if audio := el.SelectElement("audio"); audio != nil {
+				node.Audio = &AudioClip{
+					Href:      resolveFragment(base, audio.SelectAttr("src")),
+					ClipBegin: parseSMILClock(audio.SelectAttr("clipBegin")),
+					ClipEnd:   parseSMILClock(audio.SelectAttr("clipEnd")),
+					Skippable: parSkippable,
+				}
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func resolveFragment(base url.URL, href string) string {
+	if href == "" {
+		return ""
+	}
+	return base.Resolve(url.MustURLFromString(href)).String()
+}
+
+// parseSMILClock parses the SMIL clock-value grammar used by clipBegin/clipEnd
+// ("0:00:01.234", "00:00:01.234", "1.234s" or a bare seconds float) into seconds.
+func parseSMILClock(value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if strings.HasSuffix(value, "s") {
+		value = strings.TrimSuffix(value, "s")
+		f, _ := strconv.ParseFloat(value, 64)
+		return f
+	}
+	parts := strings.Split(value, ":")
+	var seconds float64
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			continue
+		}
+		seconds = seconds*60 + f
+	}
+	return seconds
+}
+
+// parseSMILDuration looks up the OPF meta that refines the given SMIL manifest item
+// ("<meta refines=\"#smilItemID\" property=\"media:duration\">"), which is how EPUB3
+// declares the duration of a single media-overlay document rather than the whole
+// publication. It returns 0 if the publisher didn't declare one for this item.
+func parseSMILDuration(packageDocument PackageDocument, smilItemID string) time.Duration {
+	refines := "#" + smilItemID
+	for _, meta := range packageDocument.Metadata.Meta {
+		if meta.Property == "media:duration" && meta.Refines == refines {
+			return time.Duration(parseSMILClock(meta.Value) * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+func parseMediaOverlayMetadata(packageDocument PackageDocument) (narrator, activeClass, playbackActiveClass string) {
+	for _, meta := range packageDocument.Metadata.Meta {
+		switch meta.Property {
+		case "media:narrator":
+			narrator = meta.Value
+		case "media:active-class":
+			activeClass = meta.Value
+		case "media:playback-active-class":
+			playbackActiveClass = meta.Value
+		}
+	}
+	return
+}
+
+// MediaOverlayFactory returns a pub.ServiceFactory producing the GuidedNavigationService
+// for an EPUB, backed by the SMIL overlay trees parseMediaOverlays extracted. This is
+// the same extension point PositionsServiceFactory and the content/iterator factories
+// plug into in Parser.Parse.
+func MediaOverlayFactory(overlays map[url.URL]MediaOverlay) pub.ServiceFactory {
+	return func(context pub.Context) pub.Service {
+		return &guidedNavigationService{overlays: overlays}
+	}
+}
+
+// guidedNavigationService implements pub.Service (via pub.GuidedNavigationService_Name),
+// serving the parsed media-overlay tree for each reading-order link that declares one.
+type guidedNavigationService struct {
+	overlays map[url.URL]MediaOverlay
+}
+
+// Links lists the reading-order resources that carry a parsed overlay tree.
+func (s *guidedNavigationService) Links() manifest.LinkList {
+	links := make(manifest.LinkList, 0, len(s.overlays))
+	for href := range s.overlays {
+		links = append(links, manifest.Link{
+			Href:      manifest.NewHREF(href),
+			MediaType: &mediatype.JSON,
+		})
+	}
+	return links
+}
+
+// Get serves the guided-navigation JSON document (the sync narration tree plus
+// duration/narrator/active-class metadata) for the reading-order link the caller
+// requests, or nil if link has no parsed overlay.
+func (s *guidedNavigationService) Get(link manifest.Link) fetcher.Resource {
+	overlay, ok := s.overlays[link.URL(nil, nil)]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(mediaOverlayToJSON(overlay))
+	if err != nil {
+		return nil
+	}
+	return fetcher.NewBytesResource(link, data)
+}
+
+func mediaOverlayToJSON(overlay MediaOverlay) map[string]interface{} {
+	return map[string]interface{}{
+		"nodes":               mediaOverlayNodesToJSON(overlay.Nodes),
+		"duration":            overlay.Duration.Seconds(),
+		"narrator":            overlay.Narrator,
+		"activeClass":         overlay.ActiveClass,
+		"playbackActiveClass": overlay.PlaybackActiveClass,
+	}
+}
+
+func mediaOverlayNodesToJSON(nodes []MediaOverlayNode) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		entry := map[string]interface{}{"role": node.Role}
+		if node.Text != "" {
+			entry["text"] = node.Text
+		}
+		if node.Audio != nil {
+			entry["audio"] = map[string]interface{}{
+				"href":      node.Audio.Href,
+				"clipBegin": node.Audio.ClipBegin,
+				"clipEnd":   node.Audio.ClipEnd,
+				"skippable": node.Audio.Skippable,
+			}
+		}
+		if len(node.Children) > 0 {
+			entry["children"] = mediaOverlayNodesToJSON(node.Children)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
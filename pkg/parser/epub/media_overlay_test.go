@@ -0,0 +1,170 @@
+package epub
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/pub"
+	"github.com/readium/go-toolkit/pkg/util/url"
+)
+
+func TestParseSMILClock(t *testing.T) {
+	cases := map[string]float64{
+		"":             0,
+		"1.5s":         1.5,
+		"3.25":         3.25,
+		"00:00:01.234": 1.234,
+		"0:01:00":      60,
+		"1:00:00":      3600,
+	}
+	for in, want := range cases {
+		if got := parseSMILClock(in); got != want {
+			t.Errorf("parseSMILClock(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func mustParseSMILBody(t *testing.T, smil string) *xmlquery.Node {
+	t.Helper()
+	doc, err := xmlquery.Parse(strings.NewReader(smil))
+	if err != nil {
+		t.Fatalf("parsing SMIL fixture: %v", err)
+	}
+	body := doc.SelectElement("//body")
+	if body == nil {
+		t.Fatalf("SMIL fixture has no <body>: %s", smil)
+	}
+	return body
+}
+
+func TestParseSMILChildrenSkippability(t *testing.T) {
+	const smil = `<smil xmlns="http://www.w3.org/ns/SMIL" xmlns:epub="http://www.idpf.org/2007/ops">
+  <body>
+    <par id="p1">
+      <text src="chapter1.xhtml#s1"/>
+      <audio src="chapter1.mp3" clipBegin="0s" clipEnd="1s"/>
+    </par>
+    <seq epub:type="footnote">
+      <par id="p2">
+        <text src="chapter1.xhtml#s2"/>
+        <audio src="chapter1.mp3" clipBegin="1s" clipEnd="3s"/>
+      </par>
+    </seq>
+  </body>
+</smil>`
+
+	body := mustParseSMILBody(t, smil)
+	nodes := parseSMILChildren(body, url.MustURLFromString("chapter1.smil"), false)
+
+	if len(nodes) != 2 {
+		t.Fatalf("parseSMILChildren returned %d top-level nodes, want 2", len(nodes))
+	}
+	if nodes[0].Audio == nil || nodes[0].Audio.Skippable {
+		t.Errorf("plain par marked skippable: %+v", nodes[0].Audio)
+	}
+	seq := nodes[1]
+	if seq.Role != "seq" || len(seq.Children) != 1 {
+		t.Fatalf("expected a single-child seq node, got %+v", seq)
+	}
+	if seq.Children[0].Audio == nil || !seq.Children[0].Audio.Skippable {
+		t.Errorf("par inside epub:type=\"footnote\" seq not marked skippable: %+v", seq.Children[0].Audio)
+	}
+}
+
+func TestParseSMILChildrenPreservesDocumentOrder(t *testing.T) {
+	const smil = `<smil xmlns="http://www.w3.org/ns/SMIL" xmlns:epub="http://www.idpf.org/2007/ops">
+  <body>
+    <seq>
+      <par id="p1"><text src="chapter1.xhtml#s1"/></par>
+    </seq>
+    <par id="p2"><text src="chapter1.xhtml#s2"/></par>
+    <seq>
+      <par id="p3"><text src="chapter1.xhtml#s3"/></par>
+    </seq>
+  </body>
+</smil>`
+
+	body := mustParseSMILBody(t, smil)
+	nodes := parseSMILChildren(body, url.MustURLFromString("chapter1.smil"), false)
+
+	wantRoles := []string{"seq", "par", "seq"}
+	if len(nodes) != len(wantRoles) {
+		t.Fatalf("parseSMILChildren returned %d nodes, want %d", len(nodes), len(wantRoles))
+	}
+	for i, want := range wantRoles {
+		if nodes[i].Role != want {
+			t.Errorf("nodes[%d].Role = %q, want %q (document order must be preserved)", i, nodes[i].Role, want)
+		}
+	}
+}
+
+func TestMediaOverlayFactoryLinksAndGet(t *testing.T) {
+	link := manifest.Link{Href: manifest.MustNewHREFFromString("chapter1.xhtml", false)}
+	overlay := MediaOverlay{
+		Nodes: []MediaOverlayNode{
+			{
+				Role: "par",
+				Text: "chapter1.xhtml#sentence1",
+				Audio: &AudioClip{
+					Href:      "chapter1.mp3",
+					ClipBegin: 0,
+					ClipEnd:   1.5,
+					Skippable: false,
+				},
+			},
+		},
+		Duration:            90 * time.Second,
+		Narrator:            "Jane Narrator",
+		ActiveClass:         "-epub-media-overlay-active",
+		PlaybackActiveClass: "-epub-media-overlay-playing",
+	}
+	overlays := map[url.URL]MediaOverlay{link.URL(nil, nil): overlay}
+
+	factory := MediaOverlayFactory(overlays)
+	service := factory(pub.Context{})
+
+	links := service.Links()
+	if len(links) != 1 {
+		t.Fatalf("Links() returned %d links, want 1", len(links))
+	}
+
+	resource := service.Get(link)
+	if resource == nil {
+		t.Fatal("Get() returned nil for a link with a parsed overlay")
+	}
+	data, err := resource.Read()
+	if err != nil {
+		t.Fatalf("resource.Read(): %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("guided-navigation resource body is empty")
+	}
+
+	missing := manifest.Link{Href: manifest.MustNewHREFFromString("chapter2.xhtml", false)}
+	if service.Get(missing) != nil {
+		t.Error("Get() returned a resource for a link with no parsed overlay")
+	}
+}
+
+func TestParseSMILDuration(t *testing.T) {
+	packageDocument := PackageDocument{
+		Metadata: Metadata{
+			Meta: []Meta{
+				{Property: "media:duration", Value: "0:32:29.000", Refines: "#smil1"},
+				{Property: "media:duration", Value: "1:00:00.000", Refines: "#smil2"},
+			},
+		},
+	}
+	if got, want := parseSMILDuration(packageDocument, "smil1"), 32*time.Minute+29*time.Second; got != want {
+		t.Errorf("parseSMILDuration(smil1) = %v, want %v", got, want)
+	}
+	if got, want := parseSMILDuration(packageDocument, "smil2"), time.Hour; got != want {
+		t.Errorf("parseSMILDuration(smil2) = %v, want %v", got, want)
+	}
+	if got := parseSMILDuration(packageDocument, "unknown"); got != 0 {
+		t.Errorf("parseSMILDuration(unknown) = %v, want 0", got)
+	}
+}
@@ -7,12 +7,39 @@ import (
 	"github.com/readium/go-toolkit/pkg/fetcher"
 	"github.com/readium/go-toolkit/pkg/manifest"
 	"github.com/readium/go-toolkit/pkg/mediatype"
+	"github.com/readium/go-toolkit/pkg/metadata"
 	"github.com/readium/go-toolkit/pkg/pub"
 	"github.com/readium/go-toolkit/pkg/util/url"
 )
 
 type Parser struct {
 	reflowablePositionsStrategy ReflowableStrategy
+	// passphrase unlocks a detected LCP content protection, if any (Adobe ADEPT is
+	// detected but never unlocked). Left empty, protected publications still parse, but
+	// their resources stay encrypted.
+	passphrase string
+	// metadataConfig controls external metadata enrichment; its zero value is Online
+	// mode with no providers, which enrich() treats as a no-op.
+	metadataConfig metadata.Config
+	// injectKoboSpans re-wraps every sentence of a plain (non-KEPUB) EPUB's content
+	// documents in koboSpan markers, so Kobo-style clients get the same reading-progress
+	// model they'd get from a real KEPUB.
+	injectKoboSpans bool
+}
+
+// WithKoboSpanInjection returns a copy of p that injects koboSpan markers into plain
+// EPUBs that don't already have them, for serving to Kobo-style clients.
+func (p Parser) WithKoboSpanInjection(inject bool) Parser {
+	p.injectKoboSpans = inject
+	return p
+}
+
+// WithMetadataEnrichment returns a copy of p that augments missing metadata fields
+// (description, subjects, publication date, cover URL, page count) via cfg at the end of
+// Parse.
+func (p Parser) WithMetadataEnrichment(cfg metadata.Config) Parser {
+	p.metadataConfig = cfg
+	return p
 }
 
 func NewParser(strategy ReflowableStrategy) Parser {
@@ -24,6 +51,14 @@ func NewParser(strategy ReflowableStrategy) Parser {
 	}
 }
 
+// NewParserWithPassphrase is like NewParser, but additionally tries to unlock a detected
+// Readium LCP license with passphrase so protected resources can be decrypted on read.
+func NewParserWithPassphrase(strategy ReflowableStrategy, passphrase string) Parser {
+	p := NewParser(strategy)
+	p.passphrase = passphrase
+	return p
+}
+
 // Parse implements PublicationParser
 func (p Parser) Parse(asset asset.PublicationAsset, f fetcher.Fetcher) (*pub.Builder, error) {
 	fallbackTitle := asset.Name()
@@ -37,8 +72,6 @@ func (p Parser) Parse(asset asset.PublicationAsset, f fetcher.Fetcher) (*pub.Bui
 		return nil, err
 	}
 
-	// Detect DRM
-
 	opfXmlDocument, errx := f.Get(manifest.Link{Href: manifest.NewHREF(opfPath)}).ReadAsXML(map[string]string{
 		NamespaceOPF:                         "opf",
 		NamespaceDC:                          "dc",
@@ -54,11 +87,13 @@ func (p Parser) Parse(asset asset.PublicationAsset, f fetcher.Fetcher) (*pub.Bui
 		return nil, errors.Wrap(err, "invalid OPF file")
 	}
 
+	encryptionData := parseEncryptionData(f)
+
 	manifest := PublicationFactory{
 		FallbackTitle:   fallbackTitle,
 		PackageDocument: *packageDocument,
 		NavigationData:  parseNavigationData(*packageDocument, f),
-		EncryptionData:  parseEncryptionData(f),
+		EncryptionData:  encryptionData,
 		DisplayOptions:  parseDisplayOptions(f),
 	}.Create()
 
@@ -67,12 +102,26 @@ func (p Parser) Parse(asset asset.PublicationAsset, f fetcher.Fetcher) (*pub.Bui
 		ffetcher = fetcher.NewTransformingFetcher(f, NewDeobfuscator(manifest.Metadata.Identifier).Transform)
 	}
 
+	protection := detectDRM(f, encryptionData)
+	ffetcher = applyDRM(&manifest, ffetcher, protection, p.passphrase)
+
+	mediaOverlays := parseMediaOverlays(*packageDocument, f)
+
+	enrich(&manifest.Metadata, p.metadataConfig)
+
+	positionsStrategy := p.reflowablePositionsStrategy
+	if IsKEPUB(fallbackTitle, f) {
+		positionsStrategy = KoboPositionsStrategy
+	} else if p.injectKoboSpans {
+		ffetcher = fetcher.NewTransformingFetcher(ffetcher, NewKoboSpanInjector())
+	}
+
 	builder := pub.NewServicesBuilder(map[string]pub.ServiceFactory{
-		pub.PositionsService_Name: PositionsServiceFactory(p.reflowablePositionsStrategy),
+		pub.PositionsService_Name: PositionsServiceFactory(positionsStrategy),
 		pub.ContentService_Name: pub.DefaultContentServiceFactory([]iterator.ResourceContentIteratorFactory{
 			iterator.HTMLFactory(),
 		}),
-		pub.GuidedNavigationService_Name: MediaOverlayFactory(),
+		pub.GuidedNavigationService_Name: MediaOverlayFactory(mediaOverlays),
 	})
 	return pub.NewBuilder(manifest, ffetcher, builder), nil
 }
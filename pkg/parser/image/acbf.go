@@ -0,0 +1,202 @@
+// Package image holds format-specific helpers for ImageParser, the Divina publication
+// parser over unstructured archives of bitmap files.
+package image
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Document is the subset of an ACBF (Advanced Comic Book Format) sidecar that's relevant
+// to authoring a Divina publication: book metadata, page order and transitions, and the
+// frame/text-layer regions of each page.
+type Document struct {
+	BookInfo BookInfo
+	Pages    []Page
+}
+
+// BookInfo is the content of an ACBF <book-info> element.
+type BookInfo struct {
+	Titles        map[string]string // language -> title
+	Authors       []string
+	Genres        []string
+	Languages     []string
+	Annotations   map[string]string // language -> annotation text
+	CoverPageHref string
+	ContentRating string
+}
+
+// Page is a single ACBF <page>: its image, how it transitions from the previous page,
+// and the frame/text-layer regions drawn over it.
+type Page struct {
+	ImageHref  string
+	Transition string
+	Frames     []Frame
+	TextLayers []TextLayer
+}
+
+// Frame is a panel region on a page, described as a polygon of points.
+type Frame struct {
+	Points []Point
+}
+
+// TextLayer is a language-specific set of text regions overlaid on a page.
+type TextLayer struct {
+	Language string
+	Regions  []Frame
+}
+
+// Point is a single vertex of a frame/text-region polygon, in page pixel coordinates.
+type Point struct {
+	X, Y int
+}
+
+// acbf XML document shape, unexported: Document above is the normalized result we hand
+// to callers, this is only the wire format.
+type acbfXML struct {
+	XMLName  xml.Name `xml:"ACBF"`
+	BookInfo struct {
+		BookTitle []struct {
+			Lang  string `xml:"lang,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"book-title"`
+		Author []struct {
+			FirstName string `xml:"first-name"`
+			LastName  string `xml:"last-name"`
+			Nickname  string `xml:"nickname"`
+		} `xml:"author"`
+		Genre     []string `xml:"genre"`
+		Languages struct {
+			TextLayer []struct {
+				Lang string `xml:"lang,attr"`
+			} `xml:"text-layer"`
+		} `xml:"languages"`
+		Annotation []struct {
+			Lang string   `xml:"lang,attr"`
+			P    []string `xml:"p"`
+		} `xml:"annotation"`
+		Coverpage struct {
+			Image struct {
+				Href string `xml:"href,attr"`
+			} `xml:"image"`
+		} `xml:"coverpage"`
+		ContentRating []struct {
+			Value string `xml:",chardata"`
+		} `xml:"content-rating"`
+	} `xml:"meta-data>book-info"`
+	Body struct {
+		Page []struct {
+			Transition string `xml:"transition,attr"`
+			Image      struct {
+				Href string `xml:"href,attr"`
+			} `xml:"image"`
+			Frame []struct {
+				Points string `xml:"points,attr"`
+			} `xml:"frame"`
+			TextLayer []struct {
+				Lang     string `xml:"lang,attr"`
+				TextArea []struct {
+					Points string `xml:"points,attr"`
+				} `xml:"text-area"`
+			} `xml:"text-layer"`
+		} `xml:"page"`
+	} `xml:"body"`
+}
+
+// Parse decodes an ACBF sidecar (the raw bytes of the *.acbf file found in the archive).
+func Parse(data []byte) (*Document, error) {
+	var raw acbfXML
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid ACBF document")
+	}
+
+	doc := &Document{
+		BookInfo: BookInfo{
+			Titles:        make(map[string]string),
+			Annotations:   make(map[string]string),
+			Genres:        raw.BookInfo.Genre,
+			CoverPageHref: raw.BookInfo.Coverpage.Image.Href,
+		},
+	}
+
+	for _, t := range raw.BookInfo.BookTitle {
+		lang := t.Lang
+		if lang == "" {
+			lang = "en"
+		}
+		doc.BookInfo.Titles[lang] = t.Value
+	}
+	for _, a := range raw.BookInfo.Author {
+		name := a.Nickname
+		if name == "" {
+			name = fullName(a.FirstName, a.LastName)
+		}
+		if name != "" {
+			doc.BookInfo.Authors = append(doc.BookInfo.Authors, name)
+		}
+	}
+	for _, l := range raw.BookInfo.Languages.TextLayer {
+		doc.BookInfo.Languages = append(doc.BookInfo.Languages, l.Lang)
+	}
+	for _, a := range raw.BookInfo.Annotation {
+		lang := a.Lang
+		if lang == "" {
+			lang = "en"
+		}
+		doc.BookInfo.Annotations[lang] = joinLines(a.P)
+	}
+	if len(raw.BookInfo.ContentRating) > 0 {
+		doc.BookInfo.ContentRating = raw.BookInfo.ContentRating[0].Value
+	}
+
+	for _, p := range raw.Body.Page {
+		page := Page{
+			ImageHref:  p.Image.Href,
+			Transition: p.Transition,
+		}
+		for _, f := range p.Frame {
+			page.Frames = append(page.Frames, Frame{Points: parsePoints(f.Points)})
+		}
+		for _, tl := range p.TextLayer {
+			layer := TextLayer{Language: tl.Lang}
+			for _, ta := range tl.TextArea {
+				layer.Regions = append(layer.Regions, Frame{Points: parsePoints(ta.Points)})
+			}
+			page.TextLayers = append(page.TextLayers, layer)
+		}
+		doc.Pages = append(doc.Pages, page)
+	}
+
+	return doc, nil
+}
+
+func fullName(first, last string) string {
+	return strings.TrimSpace(first + " " + last)
+}
+
+func joinLines(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// parsePoints reads an ACBF "x1,y1 x2,y2 ..." polygon attribute. Malformed vertices are
+// skipped rather than failing the whole document, since a bad frame shouldn't sink the
+// publication's metadata.
+func parsePoints(attr string) []Point {
+	var points []Point
+	for _, pair := range strings.Fields(attr) {
+		coords := strings.SplitN(pair, ",", 2)
+		if len(coords) != 2 {
+			continue
+		}
+		x, errX := strconv.Atoi(coords[0])
+		y, errY := strconv.Atoi(coords[1])
+		if errX != nil || errY != nil {
+			continue
+		}
+		points = append(points, Point{X: x, Y: y})
+	}
+	return points
+}
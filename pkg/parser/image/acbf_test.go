@@ -0,0 +1,113 @@
+package image
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<ACBF>
+  <meta-data>
+    <book-info>
+      <book-title lang="en">Example Comic</book-title>
+      <author><first-name>Jane</first-name><last-name>Doe</last-name></author>
+      <author><nickname>Anon</nickname></author>
+      <genre>superhero</genre>
+      <languages><text-layer lang="en"/><text-layer lang="fr"/></languages>
+      <annotation lang="en"><p>First line.</p><p>Second line.</p></annotation>
+      <coverpage><image href="cover.jpg"/></coverpage>
+      <content-rating>Teen</content-rating>
+    </book-info>
+  </meta-data>
+  <body>
+    <page transition="fade">
+      <image href="page001.jpg"/>
+      <frame points="10,10 100,10 100,100 10,100"/>
+      <text-layer lang="en">
+        <text-area points="20,20 50,20 50,50"/>
+      </text-layer>
+    </page>
+  </body>
+</ACBF>`)
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	if doc.BookInfo.Titles["en"] != "Example Comic" {
+		t.Errorf("Titles[en] = %q, want %q", doc.BookInfo.Titles["en"], "Example Comic")
+	}
+	if want := []string{"Jane Doe", "Anon"}; !equalStrings(doc.BookInfo.Authors, want) {
+		t.Errorf("Authors = %v, want %v", doc.BookInfo.Authors, want)
+	}
+	if want := []string{"superhero"}; !equalStrings(doc.BookInfo.Genres, want) {
+		t.Errorf("Genres = %v, want %v", doc.BookInfo.Genres, want)
+	}
+	if want := []string{"en", "fr"}; !equalStrings(doc.BookInfo.Languages, want) {
+		t.Errorf("Languages = %v, want %v", doc.BookInfo.Languages, want)
+	}
+	if doc.BookInfo.Annotations["en"] != "First line.\nSecond line." {
+		t.Errorf("Annotations[en] = %q, want %q", doc.BookInfo.Annotations["en"], "First line.\nSecond line.")
+	}
+	if doc.BookInfo.CoverPageHref != "cover.jpg" {
+		t.Errorf("CoverPageHref = %q, want %q", doc.BookInfo.CoverPageHref, "cover.jpg")
+	}
+	if doc.BookInfo.ContentRating != "Teen" {
+		t.Errorf("ContentRating = %q, want %q", doc.BookInfo.ContentRating, "Teen")
+	}
+
+	if len(doc.Pages) != 1 {
+		t.Fatalf("Pages = %d, want 1", len(doc.Pages))
+	}
+	page := doc.Pages[0]
+	if page.ImageHref != "page001.jpg" || page.Transition != "fade" {
+		t.Errorf("page = %+v", page)
+	}
+	if len(page.Frames) != 1 || len(page.Frames[0].Points) != 4 {
+		t.Fatalf("Frames = %+v", page.Frames)
+	}
+	if len(page.TextLayers) != 1 || page.TextLayers[0].Language != "en" || len(page.TextLayers[0].Regions) != 1 {
+		t.Fatalf("TextLayers = %+v", page.TextLayers)
+	}
+}
+
+func TestParseDefaultsUntaggedLanguageToEnglish(t *testing.T) {
+	doc, err := Parse([]byte(`<ACBF><meta-data><book-info>
+		<book-title>Untitled</book-title>
+		<annotation><p>Plain annotation.</p></annotation>
+	</book-info></meta-data><body></body></ACBF>`))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if doc.BookInfo.Titles["en"] != "Untitled" {
+		t.Errorf("Titles[en] = %q, want %q", doc.BookInfo.Titles["en"], "Untitled")
+	}
+	if doc.BookInfo.Annotations["en"] != "Plain annotation." {
+		t.Errorf("Annotations[en] = %q, want %q", doc.BookInfo.Annotations["en"], "Plain annotation.")
+	}
+}
+
+func TestParsePointsSkipsMalformedVertices(t *testing.T) {
+	points := parsePoints("10,10 bad 20,bad 30,30")
+	want := []Point{{X: 10, Y: 10}, {X: 30, Y: 30}}
+	if len(points) != len(want) || points[0] != want[0] || points[1] != want[1] {
+		t.Errorf("parsePoints() = %+v, want %+v", points, want)
+	}
+}
+
+func TestParseInvalidXML(t *testing.T) {
+	if _, err := Parse([]byte("not xml")); err == nil {
+		t.Error("Parse() of invalid XML should have failed")
+	}
+}
+
+func equalStrings(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package image
+
+import (
+	"strings"
+
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+// Apply merges an ACBF Document into a Divina manifest that was built from the archive's
+// bitmap entries: it reorders the reading order to follow the ACBF page order (instead of
+// alphabetical filename sort), fills in localized title/authors/subjects/languages, and
+// attaches frame and text-layer regions to each page's Link.
+func Apply(m *manifest.Manifest, doc *Document) {
+	if doc == nil {
+		return
+	}
+
+	applyBookInfo(m, doc.BookInfo)
+
+	if len(doc.Pages) == 0 {
+		return
+	}
+
+	byHref := make(map[string]manifest.Link, len(m.ReadingOrder))
+	for _, link := range m.ReadingOrder {
+		byHref[hrefKey(link.Href.String())] = link
+	}
+
+	ordered := make(manifest.LinkList, 0, len(doc.Pages))
+	seen := make(map[string]struct{}, len(doc.Pages))
+	for _, page := range doc.Pages {
+		key := hrefKey(page.ImageHref)
+		if _, dup := seen[key]; dup {
+			continue // a page listed twice in the ACBF must not appear twice in the reading order
+		}
+		link, ok := byHref[key]
+		if !ok {
+			continue
+		}
+		ordered = append(ordered, withPageLocations(link, page))
+		seen[key] = struct{}{}
+	}
+
+	// Pages the ACBF didn't mention (e.g. extras) are appended after the authored order
+	// so nothing from the archive is silently dropped.
+	for _, link := range m.ReadingOrder {
+		if _, ok := seen[hrefKey(link.Href.String())]; !ok {
+			ordered = append(ordered, link)
+		}
+	}
+
+	// Clear any "cover" rel every entry already carries (e.g. ImageParser marks the
+	// alphabetically-first page as cover before ACBF reordering runs) so reordering never
+	// leaves two entries both marked cover, regardless of whether the ACBF below actually
+	// names a cover page.
+	for i := range ordered {
+		if len(ordered[i].Rels) == 1 && ordered[i].Rels[0] == "cover" {
+			ordered[i].Rels = nil
+		}
+	}
+
+	coverIndex := 0
+	if doc.BookInfo.CoverPageHref != "" {
+		if cover, ok := byHref[hrefKey(doc.BookInfo.CoverPageHref)]; ok {
+			for i := range ordered {
+				if hrefKey(ordered[i].Href.String()) == hrefKey(cover.Href.String()) {
+					coverIndex = i
+					break
+				}
+			}
+		}
+	}
+	if len(ordered) > 0 {
+		ordered[coverIndex].Rels = []string{"cover"}
+	}
+
+	m.ReadingOrder = ordered
+}
+
+func applyBookInfo(m *manifest.Manifest, info BookInfo) {
+	if len(info.Titles) > 0 {
+		localized := manifest.LocalizedString{Translations: make(map[string]string, len(info.Titles))}
+		for lang, title := range info.Titles {
+			localized.Translations[lang] = title
+		}
+		m.Metadata.LocalizedTitle = localized
+	}
+	for _, author := range info.Authors {
+		m.Metadata.Author = append(m.Metadata.Author, manifest.Contributor{
+			LocalizedName: manifest.NewLocalizedStringFromString(author),
+		})
+	}
+	for _, genre := range info.Genres {
+		m.Metadata.Subject = append(m.Metadata.Subject, manifest.Subject{
+			LocalizedName: manifest.NewLocalizedStringFromString(genre),
+		})
+	}
+	for _, lang := range info.Languages {
+		m.Metadata.Language = append(m.Metadata.Language, lang)
+	}
+	if annotation, ok := firstAnnotation(info.Annotations); ok {
+		m.Metadata.Description = annotation
+	}
+}
+
+func firstAnnotation(annotations map[string]string) (string, bool) {
+	if text, ok := annotations["en"]; ok {
+		return text, true
+	}
+	for _, text := range annotations {
+		return text, true
+	}
+	return "", false
+}
+
+// withPageLocations copies link and attaches the ACBF frame/text-layer regions as the
+// "frames" and "textLayers" entries of its Properties.Other, so a reading app can jump to
+// a panel or caption directly. No alternate links or Locations are added for them.
+func withPageLocations(link manifest.Link, page Page) manifest.Link {
+	if len(page.Frames) == 0 && len(page.TextLayers) == 0 {
+		return link
+	}
+	if link.Properties.Other == nil {
+		link.Properties.Other = make(map[string]interface{})
+	}
+	link.Properties.Other["frames"] = framesToProperty(page.Frames)
+	if len(page.TextLayers) > 0 {
+		link.Properties.Other["textLayers"] = textLayersToProperty(page.TextLayers)
+	}
+	return link
+}
+
+func framesToProperty(frames []Frame) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(frames))
+	for _, f := range frames {
+		out = append(out, map[string]interface{}{"points": pointsToProperty(f.Points)})
+	}
+	return out
+}
+
+func textLayersToProperty(layers []TextLayer) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(layers))
+	for _, l := range layers {
+		out = append(out, map[string]interface{}{
+			"language": l.Language,
+			"regions":  framesToProperty(l.Regions),
+		})
+	}
+	return out
+}
+
+func pointsToProperty(points []Point) [][2]int {
+	out := make([][2]int, 0, len(points))
+	for _, p := range points {
+		out = append(out, [2]int{p.X, p.Y})
+	}
+	return out
+}
+
+func hrefKey(href string) string {
+	return strings.TrimPrefix(href, "/")
+}
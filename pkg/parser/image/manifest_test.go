@@ -0,0 +1,146 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/readium/go-toolkit/pkg/manifest"
+)
+
+func linkFor(href string) manifest.Link {
+	return manifest.Link{Href: manifest.MustNewHREFFromString(href, false)}
+}
+
+func TestApplyReordersReadingOrderAndAppendsExtras(t *testing.T) {
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{
+		linkFor("page002.jpg"),
+		linkFor("extra.jpg"),
+		linkFor("page001.jpg"),
+	}}
+	doc := &Document{Pages: []Page{
+		{ImageHref: "page001.jpg"},
+		{ImageHref: "page002.jpg"},
+	}}
+
+	Apply(m, doc)
+
+	hrefs := make([]string, len(m.ReadingOrder))
+	for i, link := range m.ReadingOrder {
+		hrefs[i] = link.Href.String()
+	}
+	want := []string{"page001.jpg", "page002.jpg", "extra.jpg"}
+	if !equalStrings(hrefs, want) {
+		t.Fatalf("ReadingOrder hrefs = %v, want %v", hrefs, want)
+	}
+	if len(m.ReadingOrder[0].Rels) != 1 || m.ReadingOrder[0].Rels[0] != "cover" {
+		t.Errorf("first page Rels = %v, want [\"cover\"] (no coverpage declared, falls back to first page)", m.ReadingOrder[0].Rels)
+	}
+}
+
+func TestApplyHonorsDeclaredCoverOverFirstPage(t *testing.T) {
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{
+		linkFor("page001.jpg"),
+		linkFor("page002.jpg"),
+	}}
+	doc := &Document{
+		BookInfo: BookInfo{CoverPageHref: "page002.jpg"},
+		Pages: []Page{
+			{ImageHref: "page001.jpg"},
+			{ImageHref: "page002.jpg"},
+		},
+	}
+
+	Apply(m, doc)
+
+	if len(m.ReadingOrder[0].Rels) != 0 {
+		t.Errorf("page001 Rels = %v, want none", m.ReadingOrder[0].Rels)
+	}
+	if len(m.ReadingOrder[1].Rels) != 1 || m.ReadingOrder[1].Rels[0] != "cover" {
+		t.Errorf("page002 Rels = %v, want [\"cover\"]", m.ReadingOrder[1].Rels)
+	}
+}
+
+func TestApplyClearsStaleCoverRelWithNoDeclaredCoverPage(t *testing.T) {
+	// Matches ImageParser.Parse's calling convention: it marks the alphabetically-first
+	// page as cover before Apply runs, regardless of what the ACBF ends up declaring.
+	first := linkFor("page001.jpg")
+	first.Rels = []string{"cover"}
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{
+		first,
+		linkFor("page002.jpg"),
+	}}
+	doc := &Document{Pages: []Page{
+		{ImageHref: "page002.jpg"},
+		{ImageHref: "page001.jpg"},
+	}}
+
+	Apply(m, doc)
+
+	if len(m.ReadingOrder[0].Rels) != 1 || m.ReadingOrder[0].Rels[0] != "cover" {
+		t.Errorf("page002 (new first page) Rels = %v, want [\"cover\"]", m.ReadingOrder[0].Rels)
+	}
+	if len(m.ReadingOrder[1].Rels) != 0 {
+		t.Errorf("page001 (old first page) Rels = %v, want none — stale cover rel must be cleared", m.ReadingOrder[1].Rels)
+	}
+}
+
+func TestApplyDropsDuplicateImageHrefFromReadingOrder(t *testing.T) {
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{linkFor("page001.jpg")}}
+	doc := &Document{Pages: []Page{
+		{ImageHref: "page001.jpg"},
+		{ImageHref: "page001.jpg"},
+	}}
+
+	Apply(m, doc)
+
+	if len(m.ReadingOrder) != 1 {
+		t.Fatalf("ReadingOrder = %d entries, want 1 (duplicate ACBF page entry must not duplicate the link)", len(m.ReadingOrder))
+	}
+}
+
+func TestApplyIgnoresPagesNotInReadingOrder(t *testing.T) {
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{linkFor("page001.jpg")}}
+	doc := &Document{Pages: []Page{
+		{ImageHref: "missing.jpg"},
+		{ImageHref: "page001.jpg"},
+	}}
+
+	Apply(m, doc)
+
+	if len(m.ReadingOrder) != 1 || m.ReadingOrder[0].Href.String() != "page001.jpg" {
+		t.Fatalf("ReadingOrder = %+v, want only page001.jpg", m.ReadingOrder)
+	}
+}
+
+func TestApplyAttachesFrameAndTextLayerLocations(t *testing.T) {
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{linkFor("page001.jpg")}}
+	doc := &Document{Pages: []Page{
+		{
+			ImageHref: "page001.jpg",
+			Frames:    []Frame{{Points: []Point{{X: 1, Y: 2}}}},
+			TextLayers: []TextLayer{
+				{Language: "en", Regions: []Frame{{Points: []Point{{X: 3, Y: 4}}}}},
+			},
+		},
+	}}
+
+	Apply(m, doc)
+
+	other := m.ReadingOrder[0].Properties.Other
+	if other == nil {
+		t.Fatal("Properties.Other is nil, want frames/textLayers attached")
+	}
+	if _, ok := other["frames"]; !ok {
+		t.Error("Properties.Other[\"frames\"] missing")
+	}
+	if _, ok := other["textLayers"]; !ok {
+		t.Error("Properties.Other[\"textLayers\"] missing")
+	}
+}
+
+func TestApplyNilDocumentIsNoop(t *testing.T) {
+	m := &manifest.Manifest{ReadingOrder: manifest.LinkList{linkFor("page001.jpg")}}
+	Apply(m, nil)
+	if len(m.ReadingOrder) != 1 || m.ReadingOrder[0].Href.String() != "page001.jpg" {
+		t.Errorf("Apply(nil) modified the manifest: %+v", m.ReadingOrder)
+	}
+}
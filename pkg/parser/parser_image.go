@@ -1,30 +1,45 @@
 package parser
 
 import (
-	"errors"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/archive"
 	"github.com/readium/go-toolkit/pkg/asset"
 	"github.com/readium/go-toolkit/pkg/fetcher"
 	"github.com/readium/go-toolkit/pkg/internal/extensions"
 	"github.com/readium/go-toolkit/pkg/manifest"
 	"github.com/readium/go-toolkit/pkg/mediatype"
+	"github.com/readium/go-toolkit/pkg/parser/image"
 	"github.com/readium/go-toolkit/pkg/pub"
 )
 
-// Parses an image–based Publication from an unstructured archive format containing bitmap files, such as CBZ or a simple ZIP.
+// Parses an image–based Publication from an unstructured archive format containing bitmap files, such as CBZ, CBR or a simple ZIP.
 // It can also work for a standalone bitmap file.
+// CBR/RAR assets arrive from the caller as a plain, unarchived fetcher (the generic
+// archive dispatch upstream only unpacks ZIP-based formats), so Parse opens them itself
+// through archive.RARArchiveFactory before doing anything else.
 type ImageParser struct{}
 
 // Parse implements PublicationParser
-func (p ImageParser) Parse(asset asset.PublicationAsset, fetcher fetcher.Fetcher) (*pub.Builder, error) {
-	if ok, err := p.accepts(asset, fetcher); err != nil || !ok {
+func (p ImageParser) Parse(a asset.PublicationAsset, f fetcher.Fetcher) (*pub.Builder, error) {
+	if ok, err := p.accepts(a, f); err != nil || !ok {
 		return nil, err
 	}
 
-	links, err := fetcher.Links()
+	if a.MediaType().Equal(&mediatype.CBR) {
+		rarFetcher, err := openRARFetcher(a)
+		if err != nil {
+			return nil, err
+		}
+		if rarFetcher != nil {
+			f = rarFetcher
+		}
+	}
+
+	links, err := f.Links()
 	if err != nil {
 		return nil, err
 	}
@@ -49,9 +64,9 @@ func (p ImageParser) Parse(asset asset.PublicationAsset, fetcher fetcher.Fetcher
 	})
 
 	// Try to figure out the publication's title
-	title := guessPublicationTitleFromFileStructure(fetcher)
+	title := guessPublicationTitleFromFileStructure(f)
 	if title == "" {
-		title = asset.Name()
+		title = a.Name()
 	}
 
 	// First valid resource is the cover.
@@ -66,10 +81,29 @@ func (p ImageParser) Parse(asset asset.PublicationAsset, fetcher fetcher.Fetcher
 		ReadingOrder: readingOrder,
 	}
 
+	if acbf := findACBFDocument(links, f); acbf != nil {
+		image.Apply(&manifest, acbf)
+	}
+
 	builder := pub.NewServicesBuilder(map[string]pub.ServiceFactory{
 		pub.PositionsService_Name: pub.PerResourcePositionsServiceFactory(mediatype.MustNewOfString("image/*")),
 	})
-	return pub.NewBuilder(manifest, fetcher, builder), nil
+	return pub.NewBuilder(manifest, f, builder), nil
+}
+
+// openRARFetcher opens a CBR asset through archive.RARArchiveFactory and wraps the
+// resulting Archive in a Fetcher, so ImageParser can walk its pages the same way it
+// walks a ZIP-backed CBZ. It returns a nil Fetcher (and nil error) if a isn't a CBR
+// asset, so callers can treat the zero value as "use the fetcher already in hand".
+func openRARFetcher(a asset.PublicationAsset) (fetcher.Fetcher, error) {
+	arc, err := archive.NewRARArchiveFactory().Open(a, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening RAR archive")
+	}
+	if arc == nil {
+		return nil, nil
+	}
+	return fetcher.NewArchiveFetcher(arc), nil
 }
 
 var allowed_extensions_image = map[string]struct{}{"acbf": {}, "xml": {}, "txt": {}, "json": {}}
@@ -102,3 +136,25 @@ func (p ImageParser) accepts(asset asset.PublicationAsset, fetcher fetcher.Fetch
 	}
 	return true, nil
 }
+
+// findACBFDocument looks for a single *.acbf sidecar among links and parses it, returning
+// nil if none is present or it fails to parse (a missing/invalid sidecar shouldn't sink an
+// otherwise-valid Divina publication).
+func findACBFDocument(links manifest.LinkList, fetcher fetcher.Fetcher) *image.Document {
+	for _, link := range links {
+		path := link.URL(nil, nil).Path()
+		if strings.ToLower(filepath.Ext(path)) != ".acbf" {
+			continue
+		}
+		data, err := fetcher.Get(link).Read()
+		if err != nil {
+			return nil
+		}
+		doc, err := image.Parse(data)
+		if err != nil {
+			return nil
+		}
+		return doc
+	}
+	return nil
+}
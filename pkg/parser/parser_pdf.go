@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/readium/go-toolkit/pkg/asset"
+	"github.com/readium/go-toolkit/pkg/content/iterator"
+	"github.com/readium/go-toolkit/pkg/fetcher"
+	"github.com/readium/go-toolkit/pkg/manifest"
+	"github.com/readium/go-toolkit/pkg/mediatype"
+	"github.com/readium/go-toolkit/pkg/metadata"
+	"github.com/readium/go-toolkit/pkg/parser/pdf"
+	"github.com/readium/go-toolkit/pkg/pub"
+)
+
+// PDFParser builds a Readium Webpub out of a single PDF asset: one reading-order link
+// per page, metadata from the Info dictionary/XMP, and a TOC from the PDF outline.
+type PDFParser struct {
+	engine pdf.Engine
+	// metadataConfig enriches missing metadata fields from external providers; its zero
+	// value is Online mode with no providers, i.e. a no-op.
+	metadataConfig metadata.Config
+}
+
+// NewPDFParser builds a PDFParser. engine may be nil to use the default pdfcpu-backed one.
+func NewPDFParser(engine pdf.Engine) PDFParser {
+	if engine == nil {
+		engine = pdf.NewPdfcpuEngine()
+	}
+	return PDFParser{engine: engine}
+}
+
+// WithMetadataEnrichment returns a copy of p that augments missing metadata fields via
+// cfg at the end of Parse.
+func (p PDFParser) WithMetadataEnrichment(cfg metadata.Config) PDFParser {
+	p.metadataConfig = cfg
+	return p
+}
+
+// Parse implements PublicationParser
+func (p PDFParser) Parse(a asset.PublicationAsset, f fetcher.Fetcher) (*pub.Builder, error) {
+	if !a.MediaType().Equal(&mediatype.PDF) {
+		return nil, nil
+	}
+
+	source, ok := a.(interface{ Path() string })
+	if !ok {
+		return nil, errors.New("PDF publications can only be opened from a local file path")
+	}
+
+	doc, err := p.engine.Open(source.Path())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed opening PDF")
+	}
+
+	info := doc.Info()
+	title := info.Title
+	if title == "" {
+		title = a.Name()
+	}
+
+	assetName := escapeHrefName(a.Name())
+	readingOrder := make(manifest.LinkList, 0, doc.PageCount())
+	for page := 1; page <= doc.PageCount(); page++ {
+		readingOrder = append(readingOrder, manifest.Link{
+			Href:      manifest.MustNewHREFFromString(fmt.Sprintf("%s#page=%d", assetName, page), false),
+			MediaType: &mediatype.PDF,
+		})
+	}
+	if len(readingOrder) == 0 {
+		return nil, errors.New("PDF has no pages")
+	}
+
+	authors := make(manifest.Contributors, 0, len(info.Authors))
+	for _, author := range info.Authors {
+		authors = append(authors, manifest.Contributor{LocalizedName: manifest.NewLocalizedStringFromString(author)})
+	}
+
+	subjects := make([]manifest.Subject, 0, len(info.Keywords))
+	for _, keyword := range info.Keywords {
+		subjects = append(subjects, manifest.Subject{LocalizedName: manifest.NewLocalizedStringFromString(keyword)})
+	}
+
+	m := manifest.Manifest{
+		Context: manifest.Strings{manifest.WebpubManifestContext},
+		Metadata: manifest.Metadata{
+			LocalizedTitle: manifest.NewLocalizedStringFromString(title),
+			Author:         authors,
+			Subject:        subjects,
+			Description:    info.Subject,
+			ConformsTo:     manifest.Profiles{manifest.ProfilePDF},
+		},
+		ReadingOrder: readingOrder,
+		TOC:          outlineToTOC(doc.Outline(), assetName),
+	}
+	if info.Language != "" {
+		m.Metadata.Language = append(m.Metadata.Language, info.Language)
+	}
+
+	if p.metadataConfig.Mode != metadata.Offline && len(p.metadataConfig.Providers) > 0 {
+		if enriched, err := p.metadataConfig.Enrich("", title, info.Authors); err == nil {
+			metadata.Apply(&m.Metadata, enriched)
+		}
+	}
+
+	builder := pub.NewServicesBuilder(map[string]pub.ServiceFactory{
+		pub.PositionsService_Name: pub.PerResourcePositionsServiceFactory(mediatype.PDF),
+		pub.ContentService_Name: pub.DefaultContentServiceFactory([]iterator.ResourceContentIteratorFactory{
+			pdfPageImageIteratorFactory{doc: doc},
+		}),
+	})
+	return pub.NewBuilder(m, f, builder), nil
+}
+
+// escapeHrefName percent-encodes name for use as the path component of a "#page=N"
+// fragment href, so a source filename containing a literal "#" (or a space) can't be
+// mistaken for, or collide with, the fragment delimiter hrefFragment looks for.
+func escapeHrefName(name string) string {
+	return strings.ReplaceAll(url.PathEscape(name), "%2F", "/")
+}
+
+func outlineToTOC(items []pdf.OutlineItem, assetName string) manifest.LinkList {
+	toc := make(manifest.LinkList, 0, len(items))
+	for _, item := range items {
+		toc = append(toc, manifest.Link{
+			Href:     manifest.MustNewHREFFromString(fmt.Sprintf("%s#page=%d", assetName, item.PageNumber), false),
+			Title:    item.Title,
+			Children: outlineToTOC(item.Children, assetName),
+		})
+	}
+	return toc
+}
+
+// pdfPageImageIteratorFactory renders each reading-order page to an image on demand, so
+// downstream Divina-style consumers (readers that want page bitmaps, not PDF text) work
+// against a PDF publication the same way they would against a CBZ.
+type pdfPageImageIteratorFactory struct {
+	doc pdf.Document
+}
+
+func (f pdfPageImageIteratorFactory) Accepts(resource fetcher.Resource) bool {
+	return resource.Link().MediaType.Equal(&mediatype.PDF)
+}
+
+func (f pdfPageImageIteratorFactory) Create(resource fetcher.Resource, language string, startProgression float64) (iterator.Iterator, error) {
+	pageNumber, err := pageNumberFromFragment(resource.Link().Href.String())
+	if err != nil {
+		return nil, err
+	}
+	image, err := f.doc.RenderPage(pageNumber, 144)
+	if err != nil {
+		return nil, err
+	}
+	return iterator.NewSinglePageImageIterator(image, "image/png", startProgression), nil
+}
+
+func pageNumberFromFragment(href string) (int, error) {
+	var page int
+	if _, err := fmt.Sscanf(hrefFragment(href), "page=%d", &page); err != nil {
+		return 0, errors.Wrapf(err, "missing page fragment in %q", href)
+	}
+	return page, nil
+}
+
+func hrefFragment(href string) string {
+	for i := len(href) - 1; i >= 0; i-- {
+		if href[i] == '#' {
+			return href[i+1:]
+		}
+	}
+	return ""
+}
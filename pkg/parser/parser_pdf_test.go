@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEscapeHrefNamePreservesPageFragmentDelimiter(t *testing.T) {
+	name := escapeHrefName("My Book #2.pdf")
+	href := fmt.Sprintf("%s#page=%d", name, 3)
+
+	if got := hrefFragment(href); got != "page=3" {
+		t.Fatalf("hrefFragment(%q) = %q, want %q", href, got, "page=3")
+	}
+	page, err := pageNumberFromFragment(href)
+	if err != nil {
+		t.Fatalf("pageNumberFromFragment(%q) failed: %v", href, err)
+	}
+	if page != 3 {
+		t.Errorf("pageNumberFromFragment(%q) = %d, want 3", href, page)
+	}
+}
+
+func TestHrefFragment(t *testing.T) {
+	cases := map[string]string{
+		"book.pdf#page=1":     "page=1",
+		"book.pdf":            "",
+		"a%232.pdf#page=2":    "page=2",
+		"book.pdf#page=1#dup": "dup",
+	}
+	for href, want := range cases {
+		if got := hrefFragment(href); got != want {
+			t.Errorf("hrefFragment(%q) = %q, want %q", href, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package pdf abstracts the PDF library PDFParser renders and introspects documents
+// through, so the backing implementation (pdfcpu, unipdf, ...) can be swapped without
+// touching the parser itself.
+package pdf
+
+// Info is the PDF metadata PDFParser maps onto a Readium manifest: Title, Authors,
+// Subject and Keywords come from the classic Info dictionary, which has no language
+// field of its own, so Language is read from the document's XMP packet (dc:language)
+// instead.
+type Info struct {
+	Title    string
+	Authors  []string
+	Subject  string
+	Keywords []string
+	Language string
+}
+
+// OutlineItem is one entry of a PDF's outline (bookmarks), recursively.
+type OutlineItem struct {
+	Title      string
+	PageNumber int // 1-based
+	Children   []OutlineItem
+}
+
+// Document is an opened PDF, as needed to build a Readium Webpub manifest and serve
+// page images.
+type Document interface {
+	PageCount() int
+	Info() Info
+	Outline() []OutlineItem
+	// RenderPage rasterizes a 1-based page number to a PNG at the given DPI.
+	RenderPage(pageNumber int, dpi float64) ([]byte, error)
+	Close() error
+}
+
+// Engine opens a Document from a local PDF file path.
+type Engine interface {
+	Open(path string) (Document, error)
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pkg/errors"
+)
+
+// PdfcpuEngine is the default Engine, backed by github.com/pdfcpu/pdfcpu.
+type PdfcpuEngine struct{}
+
+func NewPdfcpuEngine() PdfcpuEngine {
+	return PdfcpuEngine{}
+}
+
+func (PdfcpuEngine) Open(path string) (Document, error) {
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading PDF")
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, errors.Wrap(err, "invalid PDF")
+	}
+	return &pdfcpuDocument{path: path, ctx: ctx}, nil
+}
+
+type pdfcpuDocument struct {
+	path string
+	ctx  *pdfcpu.Context
+}
+
+func (d *pdfcpuDocument) PageCount() int {
+	return d.ctx.PageCount
+}
+
+func (d *pdfcpuDocument) Info() Info {
+	info := Info{}
+	if d.ctx.Title != "" {
+		info.Title = d.ctx.Title
+	}
+	if d.ctx.Author != "" {
+		info.Authors = strings.Split(d.ctx.Author, ";")
+	}
+	if d.ctx.Subject != "" {
+		info.Subject = d.ctx.Subject
+	}
+	if d.ctx.Keywords != "" {
+		info.Keywords = strings.Split(d.ctx.Keywords, ",")
+	}
+	info.Language = d.xmpLanguage()
+	return info
+}
+
+// xmpLanguage reads dc:language out of the document's XMP metadata packet, if it has
+// one. The classic Info dictionary has no language field, so this is the only source
+// for it; a document with no XMP packet, or an XMP packet with no dc:language, yields "".
+func (d *pdfcpuDocument) xmpLanguage() string {
+	raw, err := d.ctx.XMPMeta()
+	if err != nil || len(raw) == 0 {
+		return ""
+	}
+	var packet struct {
+		RDF struct {
+			Description struct {
+				Language struct {
+					// dc:language is defined as an rdf:Bag (an unordered list), but some
+					// writers use rdf:Alt (as dc:title/dc:description do); accept either
+					// container and take its first entry.
+					Bag struct {
+						Li []string `xml:"li"`
+					} `xml:"Bag"`
+					Alt struct {
+						Li []string `xml:"li"`
+					} `xml:"Alt"`
+				} `xml:"language"`
+			} `xml:"Description"`
+		} `xml:"RDF"`
+	}
+	if err := xml.Unmarshal(raw, &packet); err != nil {
+		return ""
+	}
+	if li := packet.RDF.Description.Language.Bag.Li; len(li) > 0 {
+		return li[0]
+	}
+	if li := packet.RDF.Description.Language.Alt.Li; len(li) > 0 {
+		return li[0]
+	}
+	return ""
+}
+
+func (d *pdfcpuDocument) Outline() []OutlineItem {
+	bookmarks, err := api.Bookmarks(d.ctx)
+	if err != nil {
+		return nil
+	}
+	return convertBookmarks(bookmarks)
+}
+
+func convertBookmarks(bookmarks []pdfcpu.Bookmark) []OutlineItem {
+	out := make([]OutlineItem, 0, len(bookmarks))
+	for _, b := range bookmarks {
+		out = append(out, OutlineItem{
+			Title:      b.Title,
+			PageNumber: b.PageFrom,
+			Children:   convertBookmarks(b.Kids),
+		})
+	}
+	return out
+}
+
+func (d *pdfcpuDocument) RenderPage(pageNumber int, dpi float64) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := api.RenderPage(&buf, d.path, pageNumber, &pdfcpu.Configuration{}, dpi); err != nil {
+		return nil, errors.Wrapf(err, "failed rendering page %d", pageNumber)
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *pdfcpuDocument) Close() error {
+	return nil
+}